@@ -1,22 +1,91 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/cyberark/conjur-oss-suite-release/pkg/cli"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
 )
 
 func main() {
+	// Abort in-flight requests cleanly on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := run(ctx, os.Args[1:])
+
+	// os.Exit skips deferred calls, so the signal handling is undone first
+	stop()
+	if err != nil {
+		log.ErrLogger.Print(err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case cli.LintCommand:
+			return lint(ctx, args[1:])
+		case cli.FmtCommand:
+			return format(args[1:])
+		case cli.SemverCommand:
+			return checkSemver(ctx, args[1:])
+		}
+	}
+
 	log.OutLogger.Printf("Starting changelog parser...")
 
 	options := cli.Options{}
 
 	err := options.HandleInput()
 	if err != nil {
-		log.ErrLogger.Fatal(err)
+		return err
+	}
+
+	return cli.RunParser(ctx, options)
+}
+
+func lint(ctx context.Context, args []string) error {
+	// Keep stdout for the report so that it can be piped, e.g. into jq
+	log.OutLogger.SetOutput(os.Stderr)
+
+	options := cli.LintOptions{}
+
+	err := options.HandleInput(args)
+	if err != nil {
+		return err
+	}
+
+	return cli.RunLint(ctx, options, os.Stdout)
+}
+
+func format(args []string) error {
+	// Keep stdout for the formatted changelogs
+	log.OutLogger.SetOutput(os.Stderr)
+
+	options := cli.FmtOptions{}
+
+	err := options.HandleInput(args)
+	if err != nil {
+		return err
 	}
 
-	err = cli.RunParser(options)
+	return cli.RunFmt(options, os.Stdout)
+}
+
+func checkSemver(ctx context.Context, args []string) error {
+	// Keep stdout for the report so that it can be piped, e.g. into jq
+	log.OutLogger.SetOutput(os.Stderr)
+
+	options := cli.SemverOptions{}
+
+	err := options.HandleInput(args)
 	if err != nil {
-		log.ErrLogger.Fatal(err)
+		return err
 	}
+
+	return cli.RunSemver(ctx, options, os.Stdout)
 }
@@ -0,0 +1,109 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// OtherSection is the section of the entries that Classify can't place
+const OtherSection = "Other"
+
+// Classification records an entry without a section that Classify put into one
+type Classification struct {
+	Repo    string
+	Version string
+	Entry   Entry
+	Section string
+}
+
+// classifierRule puts entries mentioning any of its keywords into a section
+type classifierRule struct {
+	section  string
+	keywords *regexp.Regexp
+}
+
+// securityKeywords only place an entry into Security as its first word, since
+// entries like "Updated security docs" mention security in passing
+var securityKeywords = regexp.MustCompile(`(?i)\b(security|vulnerab(le|ility|ilities))\b`)
+
+var classifierRules = []classifierRule{
+	{"Deprecated", regexp.MustCompile(`(?i)\bdeprecat(e|ed|es|ing|ion)\b`)},
+	{"Removed", regexp.MustCompile(`(?i)\b(remov(e|ed|es|ing|al)|delet(e|ed|es|ing|ion)|drop(s|ped|ping)?)\b`)},
+	{"Fixed", regexp.MustCompile(`(?i)\b(fix(es|ed|ing)?|bugs?|bugfix(es)?|resolv(e|ed|es|ing)|correct(s|ed|ing)?)\b`)},
+	{"Added", regexp.MustCompile(`(?i)\b(add(ed|s|ing)?|additions?|new|introduc(e|ed|es|ing)|support(s|ed|ing)?)\b`)},
+	{"Changed", regexp.MustCompile(`(?i)\b(chang(e|ed|es|ing)|updat(e|ed|es|ing)|bump(s|ed|ing)?|upgrad(e|ed|es|ing)|` +
+		`improv(e|ed|es|ing|ements?)|refactor(s|ed|ing)?|renam(e|ed|es|ing)|replac(e|ed|es|ing))\b`)},
+}
+
+// ClassifyEntry guesses the canonical section of an entry from keywords in its
+// text, e.g. "fix", "add", "remove", "deprecate" or a CVE ID. Entries with a
+// CVE or GHSA ID, or starting with e.g. "Security", are security fixes. Other
+// than that, the first word takes precedence, so "Fixed adding users" is a
+// fix. It returns OtherSection if no keyword matches.
+func ClassifyEntry(entry Entry) string {
+	if len(entry.CVEs) > 0 || len(entry.GHSAs) > 0 {
+		return "Security"
+	}
+
+	words := strings.Fields(entry.Text)
+	if len(words) > 0 {
+		if securityKeywords.MatchString(words[0]) {
+			return "Security"
+		}
+
+		for _, rule := range classifierRules {
+			if rule.keywords.MatchString(words[0]) {
+				return rule.section
+			}
+		}
+	}
+
+	for _, rule := range classifierRules {
+		if rule.keywords.MatchString(entry.Text) {
+			return rule.section
+		}
+	}
+
+	return OtherSection
+}
+
+// Classify moves the entries that aren't under any section, i.e. those listed
+// directly under the version heading, into the sections ClassifyEntry picks.
+// It returns what it classified so that it can be reported.
+func Classify(c *VersionChangelog) []Classification {
+	var sections []Section
+	var unsectioned []Entry
+	seen := map[string]bool{}
+	for _, section := range c.Sections {
+		if section.Name == "" || section.Name == "_" {
+			unsectioned = append(unsectioned, section.Entries...)
+			continue
+		}
+
+		sections = append(sections, section)
+		for _, entry := range section.Entries {
+			seen[entry.Text] = true
+		}
+	}
+	c.Sections = sections
+
+	var classifications []Classification
+	for _, entry := range unsectioned {
+		// The `_` section may repeat the entries of the other sections
+		if seen[entry.Text] {
+			continue
+		}
+
+		section := ClassifyEntry(entry)
+		c.addEntry(section, entry)
+
+		classifications = append(classifications, Classification{
+			Repo:    c.Repo,
+			Version: c.Version,
+			Entry:   entry,
+			Section: section,
+		})
+	}
+
+	return classifications
+}
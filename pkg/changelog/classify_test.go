@@ -0,0 +1,87 @@
+package changelog
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyEntry(t *testing.T) {
+	for text, section := range map[string]string{
+		"Fixed a crash when adding users":        "Fixed",
+		"Resolves a bug in the login page":       "Fixed",
+		"Added a `--json` flag":                  "Added",
+		"Support for Kubernetes 1.18":            "Added",
+		"Addressed a race condition":             "Other",
+		"Removed the deprecated v4 API":          "Removed",
+		"The v4 API is now deprecated":           "Deprecated",
+		"Bumped Go to 1.17":                      "Changed",
+		"Upgraded nokogiri for CVE-2021-1234":    "Security",
+		"Security: escape user names in logs":    "Security",
+		"Vulnerability in the login page closed": "Security",
+		"Fixed GHSA-j6w9-fv6q-3q52":              "Security",
+		"Updated security docs":                  "Changed",
+		"Removed insecure security flag":         "Removed",
+		"Added security headers":                 "Added",
+		"Dropdown menus are sorted by name":      "Other",
+		"Dropped support for Ruby 2.5":           "Removed",
+		"Addresses of new hosts are validated":   "Added",
+		"Documentation overhaul":                 "Other",
+		"Initial release":                        "Other",
+		"Users can now rename their own entries": "Changed",
+	} {
+		assert.Equal(t, section, ClassifyEntry(NewEntry("repo", text)), text)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	markdown, err := ioutil.ReadFile("testdata/changelog.sectionless.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	changelogs, err := Parse("cyberark/conjur", string(markdown))
+	if !assert.NoError(t, err) || !assert.Len(t, changelogs, 3) {
+		return
+	}
+
+	// Entries listed directly under a version don't inherit the section of
+	// the previous version
+	assert.Equal(t, []string{"", "Added"}, sectionNames(changelogs[1].Sections))
+	assert.Equal(t, []string{""}, sectionNames(changelogs[2].Sections))
+
+	classifications := Classify(changelogs[1])
+	assert.Equal(t, []string{"Added", "Fixed", "Removed", "Security"}, sectionNames(changelogs[1].Sections))
+	assert.Equal(t, "Documented the `--json` flag", changelogs[1].Entries("Added")[0].Text)
+	assert.Equal(t, "Added a `--json` flag", changelogs[1].Entries("Added")[1].Text)
+
+	var sections []string
+	for _, classification := range classifications {
+		assert.Equal(t, "cyberark/conjur", classification.Repo)
+		assert.Equal(t, "1.1.0", classification.Version)
+		sections = append(sections, classification.Section)
+	}
+	assert.Equal(t, []string{"Fixed", "Added", "Removed", "Security"}, sections)
+
+	Classify(changelogs[2])
+	assert.Equal(t, []string{OtherSection}, sectionNames(changelogs[2].Sections))
+	assert.Len(t, changelogs[2].Entries(OtherSection), 2)
+}
+
+func TestClassifyLegacySection(t *testing.T) {
+	versionChangelog := &VersionChangelog{
+		Sections: []Section{
+			{Name: "Added", Entries: entries("add 1")},
+			{Name: "_", Entries: entries("add 1", "fix 2")},
+		},
+	}
+
+	classifications := Classify(versionChangelog)
+
+	assert.Len(t, classifications, 1)
+	assert.Equal(t, []Section{
+		{Name: "Added", Entries: entries("add 1")},
+		{Name: "Fixed", Entries: entries("fix 2")},
+	}, versionChangelog.Sections)
+}
@@ -0,0 +1,98 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Commit is a commit of a repo whose message may follow
+// [Conventional Commits](https://www.conventionalcommits.org/)
+type Commit struct {
+	SHA     string
+	Message string
+	URL     string
+}
+
+// Sections of the Conventional Commits types that are listed in changelogs.
+// The others, e.g. `docs`, `chore` or `ci`, aren't user-facing and are only
+// listed if they're breaking changes.
+var commitTypeSections = map[string]string{
+	"feat":     "Added",
+	"fix":      "Fixed",
+	"perf":     "Changed",
+	"refactor": "Changed",
+	"revert":   "Changed",
+}
+
+// Matches e.g. `feat: ...`, `fix(parser): ...` and `refactor!: ...`
+var conventionalCommitRegexp = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+
+// Matches the `BREAKING CHANGE: ...` footer of a commit message
+var breakingChangeFooterRegexp = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s*(.*)$`)
+
+// ParseCommit turns a commit into the changelog entry of its subject line,
+// e.g. `fix(parser): Handle empty lines` becomes "**parser:** Handle empty
+// lines" in the Fixed section. Breaking changes, i.e. types followed by `!` or
+// messages with a `BREAKING CHANGE:` footer, are marked as such and list the
+// footer as a nested entry. It returns false for commits that don't follow
+// Conventional Commits or aren't listed in changelogs.
+func ParseCommit(repo string, commit Commit) (string, Entry, bool) {
+	lines := strings.SplitN(strings.ReplaceAll(commit.Message, "\r\n", "\n"), "\n", 2)
+	match := conventionalCommitRegexp.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if match == nil {
+		return "", Entry{}, false
+	}
+
+	commitType, scope, description := strings.ToLower(match[1]), match[2], match[4]
+
+	footer := breakingChangeFooterRegexp.FindStringSubmatch(commit.Message)
+	breaking := match[3] == "!" || footer != nil
+
+	section, ok := commitTypeSections[commitType]
+	if !ok {
+		if !breaking {
+			return "", Entry{}, false
+		}
+		section = "Changed"
+	}
+
+	text := description
+	if scope != "" {
+		text = fmt.Sprintf("**%s:** %s", scope, text)
+	}
+	if commit.URL != "" && len(commit.SHA) >= 7 {
+		text = fmt.Sprintf("%s ([%s](%s))", text, commit.SHA[:7], commit.URL)
+	}
+
+	entry := NewEntry(repo, text)
+	entry.Breaking = entry.Breaking || breaking
+	if footer != nil && strings.TrimSpace(footer[1]) != "" {
+		entry.Children = append(entry.Children, NewEntry(repo, strings.TrimSpace(footer[1])))
+	}
+
+	return section, entry, true
+}
+
+// CommitChangelog synthesizes the changelog of a version from its commits,
+// oldest first, see ParseCommit. Sections are listed in the order they are
+// first seen in. It returns nil if none of the commits are listed.
+func CommitChangelog(repo string, version string, commits []Commit) *VersionChangelog {
+	versionChangelog := &VersionChangelog{
+		Repo:    repo,
+		Version: normalizeVersion(version),
+	}
+
+	for _, commit := range commits {
+		section, entry, ok := ParseCommit(repo, commit)
+		if ok {
+			versionChangelog.addEntry(section, entry)
+		}
+	}
+
+	if len(versionChangelog.Sections) == 0 {
+		return nil
+	}
+
+	return versionChangelog
+}
@@ -0,0 +1,82 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommit(t *testing.T) {
+	for message, expected := range map[string]struct {
+		section  string
+		text     string
+		breaking bool
+	}{
+		"feat: Add a `--json` flag":                                        {"Added", "Add a `--json` flag", false},
+		"fix(parser): Handle empty lines (#12)":                            {"Fixed", "**parser:** Handle empty lines (#12)", false},
+		"perf: Cache parsed changelogs":                                    {"Changed", "Cache parsed changelogs", false},
+		"Refactor!: Rename the output types":                               {"Changed", "Rename the output types", true},
+		"chore!: Drop support for Go 1.16":                                 {"Changed", "Drop support for Go 1.16", true},
+		"feat(api)!: Return JSON errors\n\nBody":                           {"Added", "**api:** Return JSON errors", true},
+		"fix: Reject bad input\r\n\r\nBREAKING CHANGE: Input is validated": {"Fixed", "Reject bad input", true},
+	} {
+		section, entry, ok := ParseCommit("cyberark/conjur", Commit{Message: message})
+		if !assert.True(t, ok, message) {
+			continue
+		}
+
+		assert.Equal(t, expected.section, section, message)
+		assert.Equal(t, expected.text, entry.Text, message)
+		assert.Equal(t, expected.breaking, entry.Breaking, message)
+	}
+
+	for _, message := range []string{
+		"docs: Document the lint command",
+		"chore(deps): Bump testify",
+		"Merge pull request #2 from cyberark/fix",
+		"Fixed a bug",
+		"",
+	} {
+		_, _, ok := ParseCommit("cyberark/conjur", Commit{Message: message})
+		assert.False(t, ok, message)
+	}
+}
+
+func TestParseCommitMetadata(t *testing.T) {
+	_, entry, ok := ParseCommit("cyberark/conjur", Commit{
+		SHA:     "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		Message: "fix: Close #45\n\nBREAKING CHANGE: Hosts need the `authn` permission",
+		URL:     "https://github.com/cyberark/conjur/commit/6dcb09b",
+	})
+	if !assert.True(t, ok) {
+		return
+	}
+
+	assert.Equal(t, "Close #45 ([6dcb09b](https://github.com/cyberark/conjur/commit/6dcb09b))", entry.Text)
+	assert.Equal(t, []Reference{{Repo: "cyberark/conjur", Number: 45}}, entry.References)
+	if assert.Len(t, entry.Children, 1) {
+		assert.Equal(t, "Hosts need the `authn` permission", entry.Children[0].Text)
+	}
+}
+
+func TestCommitChangelog(t *testing.T) {
+	versionChangelog := CommitChangelog("cyberark/conjur", "v1.2.3", []Commit{
+		{Message: "fix: First fix"},
+		{Message: "docs: Document it"},
+		{Message: "feat: A feature"},
+		{Message: "fix: Second fix"},
+	})
+	if !assert.NotNil(t, versionChangelog) {
+		return
+	}
+
+	assert.Equal(t, "1.2.3", versionChangelog.Version)
+	assert.Equal(t, []string{"Fixed", "Added"}, sectionNames(versionChangelog.Sections))
+	fixed := versionChangelog.Entries("Fixed")
+	if assert.Len(t, fixed, 2) {
+		assert.Equal(t, "First fix", fixed[0].Text)
+		assert.Equal(t, "Second fix", fixed[1].Text)
+	}
+
+	assert.Nil(t, CommitChangelog("cyberark/conjur", "v1.2.4", []Commit{{Message: "chore: Release"}}))
+}
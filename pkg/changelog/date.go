@@ -0,0 +1,91 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DefaultDateFormat is the Keep a Changelog date format, as a Go time layout
+const DefaultDateFormat = "2006-01-02"
+
+// ErrAmbiguousDate is returned by ParseDate for dates like `03/04/20` that
+// could be read either day or month first
+var ErrAmbiguousDate = errors.New("ambiguous date")
+
+// Date patterns that ParseDate accepts
+var yearFirstDateRegexp = regexp.MustCompile(`^(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})$`)
+var dottedDateRegexp = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})\.(\d{4}|\d{2})$`)
+var slashedDateRegexp = regexp.MustCompile(`^(\d{1,2})[-/](\d{1,2})[-/](\d{4}|\d{2})$`)
+
+// ParseDate parses the release date of a version heading. It accepts:
+//
+// - YYYY-MM-DD, as well as YYYY/MM/DD and YYYY.MM.DD
+//
+// - DD.MM.YYYY and DD.MM.YY, which are always day first
+//
+// - D/M/YYYY, M/D/YYYY and their two-digit year and dashed variants, as long
+// as the day is unambiguous, i.e. greater than 12 or the same as the month.
+// Otherwise ErrAmbiguousDate is returned.
+func ParseDate(date string) (time.Time, error) {
+	var year, month, day int
+	switch {
+	case yearFirstDateRegexp.MatchString(date):
+		match := yearFirstDateRegexp.FindStringSubmatch(date)
+		year, month, day = atoi(match[1]), atoi(match[2]), atoi(match[3])
+	case dottedDateRegexp.MatchString(date):
+		match := dottedDateRegexp.FindStringSubmatch(date)
+		year, month, day = fullYear(match[3]), atoi(match[2]), atoi(match[1])
+	case slashedDateRegexp.MatchString(date):
+		match := slashedDateRegexp.FindStringSubmatch(date)
+		first, second := atoi(match[1]), atoi(match[2])
+		year = fullYear(match[3])
+
+		switch {
+		case first == second || second > 12:
+			month, day = first, second
+		case first > 12:
+			month, day = second, first
+		default:
+			return time.Time{}, fmt.Errorf("%w: %s could be read day or month first", ErrAmbiguousDate, date)
+		}
+	default:
+		return time.Time{}, fmt.Errorf("unknown date format: %s", date)
+	}
+
+	// time.Date normalizes e.g. February 30th to March 2nd
+	parsed := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if parsed.Year() != year || int(parsed.Month()) != month || parsed.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date: %s", date)
+	}
+
+	return parsed, nil
+}
+
+// atoi converts digits, which the date patterns guarantee
+func atoi(digits string) int {
+	n, _ := strconv.Atoi(digits)
+	return n
+}
+
+// fullYear expands two-digit years to this century
+func fullYear(digits string) int {
+	year := atoi(digits)
+	if len(digits) == 2 {
+		year += 2000
+	}
+
+	return year
+}
+
+// FormatDate returns the release date in a Go time layout, or the date as
+// written if it couldn't be parsed
+func (c *VersionChangelog) FormatDate(layout string) string {
+	if c.ParsedDate.IsZero() {
+		return c.Date
+	}
+
+	return c.ParsedDate.Format(layout)
+}
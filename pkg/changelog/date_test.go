@@ -0,0 +1,44 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDate(t *testing.T) {
+	for date, expected := range map[string]time.Time{
+		"2020-01-29": time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC),
+		"2020/1/9":   time.Date(2020, time.January, 9, 0, 0, 0, 0, time.UTC),
+		"29.01.2020": time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC),
+		"03.04.20":   time.Date(2020, time.April, 3, 0, 0, 0, 0, time.UTC),
+		"1/29/2020":  time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC),
+		"29/1/20":    time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC),
+		"04-04-2020": time.Date(2020, time.April, 4, 0, 0, 0, 0, time.UTC),
+	} {
+		parsed, err := ParseDate(date)
+		if assert.NoError(t, err, date) {
+			assert.Equal(t, expected, parsed, date)
+		}
+	}
+}
+
+func TestParseDateErrors(t *testing.T) {
+	_, err := ParseDate("03/04/20")
+	assert.ErrorIs(t, err, ErrAmbiguousDate)
+
+	_, err = ParseDate("2020-02-30")
+	assert.EqualError(t, err, "invalid date: 2020-02-30")
+
+	_, err = ParseDate("Jan 29th")
+	assert.EqualError(t, err, "unknown date format: Jan 29th")
+}
+
+func TestFormatDate(t *testing.T) {
+	versionChangelog := &VersionChangelog{Date: "29.01.2020"}
+	assert.Equal(t, "29.01.2020", versionChangelog.FormatDate("Jan 2, 2006"))
+
+	versionChangelog.ParsedDate, _ = ParseDate(versionChangelog.Date)
+	assert.Equal(t, "Jan 29, 2020", versionChangelog.FormatDate("Jan 2, 2006"))
+}
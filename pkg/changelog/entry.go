@@ -0,0 +1,175 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reference is a reference to an issue or pull request, e.g. `#123` or
+// `cyberark/conjur#45`
+type Reference struct {
+	// Repo is the repo the issue belongs to. References without a repo are
+	// resolved to the repo of the changelog they appear in.
+	Repo   string
+	Number int
+}
+
+func (reference Reference) String() string {
+	return fmt.Sprintf("%s#%d", reference.Repo, reference.Number)
+}
+
+// URL links to the issue. GitHub redirects `issues/<n>` to the pull request if
+// that's what the number refers to.
+func (reference Reference) URL() string {
+	return fmt.Sprintf("https://github.com/%s/issues/%d", reference.Repo, reference.Number)
+}
+
+// Entry is a single item of a changelog section
+type Entry struct {
+	// Repo is the repo whose changelog the entry is from
+	Repo string
+	// Text is the inline markdown of the entry, without any nested list. This
+	// is what templates render.
+	Text string
+	// Markdown is the entry without the list marker as it's written back, e.g.
+	// by Format: like Text, but with reference links kept and the paragraphs
+	// separated by blank lines
+	Markdown string
+	// Line is the 1-based line of the source that the entry starts on, or 0 if
+	// it's unknown
+	Line int
+
+	References []Reference
+	URLs       []string
+	CVEs       []string
+	// GHSAs are the IDs of GitHub security advisories, e.g.
+	// `GHSA-j6w9-fv6q-3q52`
+	GHSAs    []string
+	Breaking bool
+
+	// Children are the entries of a nested list
+	Children []Entry
+}
+
+// Matches `#123` and `org/repo#123` unless they are part of a word, a path, a
+// code span or an existing markdown link (`[#123](...)`)
+var referenceRegexp = regexp.MustCompile(`(^|[^\w/&\[\x60])(([\w.-]+/[\w.-]+)?#(\d+))\b`)
+
+// Matches links to GitHub issues and pull requests
+var referenceURLRegexp = regexp.MustCompile(`^https?://github\.com/([\w.-]+/[\w.-]+)/(?:issues|pull)/(\d+)`)
+
+var urlRegexp = regexp.MustCompile(`https?://[^\s()<>\[\]]+[^\s()<>\[\].,;:!?'"]`)
+var cveRegexp = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+var ghsaRegexp = regexp.MustCompile(`(?i)\bGHSA(-[23456789cfghjmpqrvwx]{4}){3}\b`)
+
+// Matches e.g. `BREAKING: ...`, `**Breaking change**: ...`, `... (breaking)`
+// and `... is a breaking change`, but not `non-breaking change`
+var breakingRegexp = regexp.MustCompile(`(?i)^[\W_]*breaking\b|\(breaking( change)?\)|(^|[^\w-])breaking change`)
+
+// NewEntry creates an entry of a changelog of `repo`, extracting its metadata
+// from the text
+func NewEntry(repo string, text string) Entry {
+	entry := Entry{
+		Repo:     repo,
+		Text:     text,
+		Markdown: text,
+		Breaking: breakingRegexp.MatchString(text),
+	}
+
+	seenURLs := map[string]bool{}
+	for _, url := range urlRegexp.FindAllString(text, -1) {
+		if seenURLs[url] {
+			continue
+		}
+		seenURLs[url] = true
+		entry.URLs = append(entry.URLs, url)
+
+		if match := referenceURLRegexp.FindStringSubmatch(url); match != nil {
+			entry.addReference(match[1], match[2])
+		}
+	}
+
+	for _, match := range referenceRegexp.FindAllStringSubmatch(text, -1) {
+		entry.addReference(entry.referenceRepo(match[3]), match[4])
+	}
+
+	seenCVEs := map[string]bool{}
+	for _, cve := range cveRegexp.FindAllString(text, -1) {
+		cve = strings.ToUpper(cve)
+		if seenCVEs[cve] {
+			continue
+		}
+		seenCVEs[cve] = true
+		entry.CVEs = append(entry.CVEs, cve)
+	}
+
+	seenGHSAs := map[string]bool{}
+	for _, ghsa := range ghsaRegexp.FindAllString(text, -1) {
+		// GHSA IDs are written with an upper-case prefix and a lower-case ID
+		ghsa = "GHSA" + strings.ToLower(ghsa[len("GHSA"):])
+		if seenGHSAs[ghsa] {
+			continue
+		}
+		seenGHSAs[ghsa] = true
+		entry.GHSAs = append(entry.GHSAs, ghsa)
+	}
+
+	return entry
+}
+
+// referenceRepo resolves the repo of a reference
+func (entry *Entry) referenceRepo(repo string) string {
+	if repo == "" {
+		return entry.Repo
+	}
+
+	return repo
+}
+
+// addReference adds a reference unless the entry already has it
+func (entry *Entry) addReference(repo string, number string) {
+	// The regexps only match digits
+	n, _ := strconv.Atoi(number)
+
+	reference := Reference{Repo: repo, Number: n}
+	for _, existing := range entry.References {
+		if existing == reference {
+			return
+		}
+	}
+
+	entry.References = append(entry.References, reference)
+}
+
+func (entry Entry) String() string {
+	return entry.Text
+}
+
+// ChildrenMarkdown renders the nested entries as a markdown list indented by
+// `indent`. It starts with a newline so that it can directly follow the text of
+// the entry. Deeper levels are indented by 2 more spaces.
+func (entry Entry) ChildrenMarkdown(indent string) string {
+	markdown := ""
+	for _, child := range entry.Children {
+		markdown += "\n" + indent + "- " + child.Text
+		markdown += child.ChildrenMarkdown(indent + "  ")
+	}
+
+	return markdown
+}
+
+// LinkedText returns the text with every bare issue reference turned into a
+// markdown link
+func (entry Entry) LinkedText() string {
+	return referenceRegexp.ReplaceAllStringFunc(entry.Text, func(match string) string {
+		submatches := referenceRegexp.FindStringSubmatch(match)
+
+		// The regexp only matches digits
+		number, _ := strconv.Atoi(submatches[4])
+		reference := Reference{Repo: entry.referenceRepo(submatches[3]), Number: number}
+
+		return fmt.Sprintf("%s[%s](%s)", submatches[1], submatches[2], reference.URL())
+	})
+}
@@ -0,0 +1,64 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntry(t *testing.T) {
+	entry := NewEntry(
+		"cyberark/conjur",
+		"Fixed [#12](https://github.com/cyberark/conjur/pull/12), #13 and "+
+			"cyberark/secretless-broker#14 but not page#15 or a/b/c#16. "+
+			"See https://example.com/a_b.html.",
+	)
+
+	assert.Equal(t, []Reference{
+		{Repo: "cyberark/conjur", Number: 12},
+		{Repo: "cyberark/conjur", Number: 13},
+		{Repo: "cyberark/secretless-broker", Number: 14},
+	}, entry.References)
+	assert.Equal(t, []string{
+		"https://github.com/cyberark/conjur/pull/12",
+		"https://example.com/a_b.html",
+	}, entry.URLs)
+	assert.Empty(t, entry.CVEs)
+	assert.False(t, entry.Breaking)
+}
+
+func TestNewEntryAdvisories(t *testing.T) {
+	entry := NewEntry(
+		"cyberark/conjur",
+		"Upgraded rack for cve-2020-8184 and GHSA-J6W9-FV6Q-3Q52 "+
+			"(https://github.com/advisories/GHSA-j6w9-fv6q-3q52), not GHSA-1234-abcd-0000",
+	)
+
+	assert.Equal(t, []string{"CVE-2020-8184"}, entry.CVEs)
+	assert.Equal(t, []string{"GHSA-j6w9-fv6q-3q52"}, entry.GHSAs)
+}
+
+func TestNewEntryBreaking(t *testing.T) {
+	for text, breaking := range map[string]bool{
+		"BREAKING: removed the v4 API":       true,
+		"**Breaking change**: new defaults":  true,
+		"Removed the v4 API (breaking)":      true,
+		"This is a breaking change":          true,
+		"Added a non-breaking change option": false,
+		"Fixed breakingly slow startup":      false,
+	} {
+		assert.Equal(t, breaking, NewEntry("repo", text).Breaking, text)
+	}
+}
+
+func TestEntryLinkedText(t *testing.T) {
+	entry := NewEntry("cyberark/conjur", "Fixed #12 and cyberark/secretless-broker#3 ([#4](https://x))")
+
+	assert.Equal(
+		t,
+		"Fixed [#12](https://github.com/cyberark/conjur/issues/12) and "+
+			"[cyberark/secretless-broker#3](https://github.com/cyberark/secretless-broker/issues/3) "+
+			"([#4](https://x))",
+		entry.LinkedText(),
+	)
+}
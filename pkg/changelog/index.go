@@ -0,0 +1,77 @@
+package changelog
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+)
+
+// Index is a parsed changelog keyed by normalized version. It lets a full
+// CHANGELOG be parsed once and then queried for any number of versions.
+type Index map[string]*VersionChangelog
+
+// normalizeVersion strips the `v` prefix so that `v1.2.3` and `1.2.3` refer
+// to the same index entry
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
+
+// NewIndex parses a changelog and indexes all of its versions. If a version
+// appears more than once, the first (topmost) entry wins.
+func NewIndex(repo string, changelog string) (Index, error) {
+	versionChangelogs, err := Parse(repo, changelog)
+	if err != nil {
+		return nil, err
+	}
+
+	index := Index{}
+	for _, versionChangelog := range versionChangelogs {
+		version := normalizeVersion(versionChangelog.Version)
+		if _, ok := index[version]; ok {
+			continue
+		}
+
+		index[version] = versionChangelog
+	}
+
+	return index, nil
+}
+
+// Get returns the changelog for a version, with or without a `v` prefix, or
+// nil if the version is not in the index
+func (index Index) Get(version string) *VersionChangelog {
+	return index[normalizeVersion(version)]
+}
+
+// Unreleased returns the unreleased changes, or nil if the changelog has no
+// unreleased section
+func (index Index) Unreleased() *VersionChangelog {
+	return index[UnreleasedVersion]
+}
+
+// Versions returns the released versions, from lowest to highest. Versions
+// that aren't semantic versions are listed first, alphabetically.
+func (index Index) Versions() []string {
+	var versions []string
+	for version := range index {
+		if version != UnreleasedVersion {
+			versions = append(versions, version)
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		a, errA := semver.NewVersion(versions[i])
+		b, errB := semver.NewVersion(versions[j])
+		switch {
+		case errA != nil && errB != nil:
+			return versions[i] < versions[j]
+		case errA != nil || errB != nil:
+			return errA != nil
+		default:
+			return a.LessThan(*b)
+		}
+	})
+
+	return versions
+}
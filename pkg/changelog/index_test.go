@@ -0,0 +1,79 @@
+package changelog
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIndex(t *testing.T) {
+	changelog, err := ioutil.ReadFile("testdata/changelog.complex.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	index, err := NewIndex("test-repo", string(changelog))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// 8 versions and the unreleased changes
+	assert.Len(t, index, 9)
+
+	t.Run("finds versions with and without a v-prefix", func(t *testing.T) {
+		for _, version := range []string{"1.4.6", "v1.4.6"} {
+			versionChangelog := index.Get(version)
+			if !assert.NotNil(t, versionChangelog) {
+				return
+			}
+
+			assert.Equal(t, "1.4.6", versionChangelog.Version)
+			assert.Equal(t, "2020-01-21", versionChangelog.Date)
+		}
+	})
+
+	t.Run("returns nil for missing versions", func(t *testing.T) {
+		assert.Nil(t, index.Get("v9.9.9"))
+	})
+
+	t.Run("lists the versions from lowest to highest", func(t *testing.T) {
+		versions := index.Versions()
+		assert.Len(t, versions, 8)
+		assert.NotContains(t, versions, UnreleasedVersion)
+		assert.Equal(t, "1.4.6", versions[len(versions)-1])
+	})
+
+	t.Run("finds the unreleased changes", func(t *testing.T) {
+		unreleased := index.Unreleased()
+		if !assert.NotNil(t, unreleased) {
+			return
+		}
+
+		assert.True(t, unreleased.Unreleased)
+		assert.Equal(t, []string{
+			"Improved flows and rules around user creation",
+			"Kubernetes authenticator now returns 403 on unpermitted hosts instead of a 401",
+		}, []string{
+			unreleased.Entries("Changed")[0].Text,
+			unreleased.Entries("Changed")[1].Text,
+		})
+	})
+}
+
+func TestNewIndexKeepsFirstDuplicate(t *testing.T) {
+	index, err := NewIndex("test-repo", `# Changelog
+## 1.0.0 - 2020-02-02
+### Added
+- first
+
+## 1.0.0 - 2020-01-01
+### Added
+- second
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "2020-02-02", index.Get("1.0.0").Date)
+}
@@ -0,0 +1,193 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+)
+
+// Lint rules, as reported in Diagnostic.Rule
+const (
+	RuleVersionHeading      = "version-heading"
+	RuleMissingDate         = "missing-date"
+	RuleAmbiguousDate       = "ambiguous-date"
+	RuleInvalidDate         = "invalid-date"
+	RuleDateFormat          = "date-format"
+	RuleDuplicateVersion    = "duplicate-version"
+	RuleEntryOutsideSection = "entry-outside-section"
+	RuleSectionName         = "section-name"
+	RuleVersionOrder        = "version-order"
+	RuleLinkReference       = "link-reference"
+)
+
+// Diagnostic is a problem that Lint found in a changelog
+type Diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (diagnostic Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", diagnostic.File, diagnostic.Line, diagnostic.Message, diagnostic.Rule)
+}
+
+var versionHeadingRegexp = regexp.MustCompile(`^\s{0,3}##\s+(.*?)[\s#]*$`)
+var sectionHeadingRegexp = regexp.MustCompile(`^\s{0,3}###\s+(.*?)[\s#]*$`)
+var titleHeadingRegexp = regexp.MustCompile(`^\s{0,3}#\s`)
+
+// Entries have to start at the margin, anything indented is nested
+var topLevelListItemRegexp = regexp.MustCompile(`^([-*+]|\d+[.)])\s+`)
+
+// Matches thematic breaks, e.g. `---`, which look like list items
+var thematicBreakRegexp = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+
+// Matches inline links, e.g. `[1.2.3](https://...)`, and reference links, e.g.
+// `[1.2.3]`
+var inlineLinkRegexp = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+var referenceLinkRegexp = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// Like semverRgx but keeps any build metadata, e.g. `1.11.1+suite.2`, so that
+// such versions aren't reported as duplicates
+var lintVersionRegexp = regexp.MustCompile(`\[?v?([\w\d.-]+\.[\w\d.-]+[a-zA-Z0-9](\+[\w.-]*[a-zA-Z0-9])?)\]?`)
+
+var isoDateRegexp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// lintVersion is a version heading seen by Lint
+type lintVersion struct {
+	line    int
+	version *semver.Version
+}
+
+// Lint checks that a changelog follows the
+// [keep a changelog](https://keepachangelog.com/) format and returns the
+// problems it found, ordered by line. Section names are checked against
+// `aliases`, e.g. those configured for a suite, or DefaultSectionAliases if nil.
+// `file` is only used to label the diagnostics.
+func Lint(file string, changelog string, aliases SectionAliases) []Diagnostic {
+	if aliases == nil {
+		aliases = DefaultSectionAliases
+	}
+
+	lines := strings.Split(strings.ReplaceAll(changelog, "\r\n", "\n"), "\n")
+
+	var diagnostics []Diagnostic
+	report := func(line int, rule string, format string, args ...interface{}) {
+		diagnostics = append(diagnostics, Diagnostic{
+			File:    file,
+			Line:    line,
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	definitions := linkReferenceDefinitions(lines)
+
+	seenVersions := map[string]int{}
+	var previous *lintVersion
+
+	insideCodeFence := false
+	insideVersion := false
+	insideSection := false
+	for i, line := range lines {
+		lineNumber := i + 1
+
+		if codeFenceRegexp.MatchString(line) {
+			insideCodeFence = !insideCodeFence
+			continue
+		}
+		if insideCodeFence {
+			continue
+		}
+
+		switch {
+		case titleHeadingRegexp.MatchString(line):
+			insideVersion = false
+			insideSection = false
+
+		case versionHeadingRegexp.MatchString(line):
+			insideVersion = true
+			insideSection = false
+
+			heading := versionHeadingRegexp.FindStringSubmatch(line)[1]
+
+			// Headings like `[1.2.3]` need a definition for the link to resolve
+			for _, match := range referenceLinkRegexp.FindAllStringSubmatch(inlineLinkRegexp.ReplaceAllString(heading, ""), -1) {
+				if _, ok := definitions[strings.ToLower(match[1])]; !ok {
+					report(lineNumber, RuleLinkReference, "no link reference definition for [%s]", match[1])
+				}
+			}
+
+			// Parse only sees the text of links
+			text := inlineLinkRegexp.ReplaceAllString(heading, "$1")
+			if unreleasedRegexp.MatchString(text) {
+				continue
+			}
+
+			match := lintVersionRegexp.FindStringSubmatch(text)
+			if match == nil {
+				report(lineNumber, RuleVersionHeading, "no version found in heading %q", heading)
+				continue
+			}
+
+			version := normalizeVersion(match[1])
+			firstLine, duplicate := seenVersions[version]
+			if duplicate {
+				report(lineNumber, RuleDuplicateVersion, "version %s is already listed on line %d", match[1], firstLine)
+			} else {
+				seenVersions[version] = lineNumber
+			}
+
+			date := dateRegexp.FindStringSubmatch(text)
+			if date == nil {
+				report(lineNumber, RuleMissingDate, "version %s has no release date", match[1])
+			} else if _, err := ParseDate(date[1]); errors.Is(err, ErrAmbiguousDate) {
+				report(lineNumber, RuleAmbiguousDate, "release date %s of version %s could be read day or month first", date[1], match[1])
+			} else if err != nil {
+				report(lineNumber, RuleInvalidDate, "release date %s of version %s is not a valid date", date[1], match[1])
+			} else if !isoDateRegexp.MatchString(date[1]) {
+				report(lineNumber, RuleDateFormat, "release date %s of version %s is not in the YYYY-MM-DD format", date[1], match[1])
+			}
+
+			parsedVersion, err := semver.NewVersion(version)
+			if err != nil {
+				report(lineNumber, RuleVersionHeading, "%s is not a semantic version", match[1])
+				continue
+			}
+
+			// Versions are listed newest first. Duplicates are out of order by
+			// definition so they're only reported once.
+			if duplicate {
+				continue
+			}
+			if previous != nil && previous.version.LessThan(*parsedVersion) {
+				report(lineNumber, RuleVersionOrder, "version %s is listed after the older version %s on line %d", parsedVersion, previous.version, previous.line)
+			}
+			previous = &lintVersion{line: lineNumber, version: parsedVersion}
+
+		case sectionHeadingRegexp.MatchString(line):
+			insideSection = insideVersion
+
+			name := inlineLinkRegexp.ReplaceAllString(sectionHeadingRegexp.FindStringSubmatch(line)[1], "$1")
+			if !insideVersion {
+				break
+			}
+
+			canonical, ok := aliases.Canonical(name)
+			switch {
+			case !ok:
+				report(lineNumber, RuleSectionName, "non-standard section name %q (expected one of: %s)", name, strings.Join(aliases.CanonicalNames(), ", "))
+			case canonical != name:
+				report(lineNumber, RuleSectionName, "section %q should be named %q", name, canonical)
+			}
+
+		case insideVersion && !insideSection && topLevelListItemRegexp.MatchString(line) && !thematicBreakRegexp.MatchString(line):
+			report(lineNumber, RuleEntryOutsideSection, "entry is not under a ### section")
+		}
+	}
+
+	return diagnostics
+}
@@ -0,0 +1,95 @@
+package changelog
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	file := "testdata/changelog.lint.md"
+	markdown, err := ioutil.ReadFile(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var actual []string
+	for _, diagnostic := range Lint(file, string(markdown), DefaultSectionAliases) {
+		actual = append(actual, diagnostic.String())
+	}
+
+	assert.Equal(t, []string{
+		"testdata/changelog.lint.md:5: entry is not under a ### section (entry-outside-section)",
+		"testdata/changelog.lint.md:7: no link reference definition for [1.5.0] (link-reference)",
+		`testdata/changelog.lint.md:11: section "Bug Fixes" should be named "Fixed" (section-name)`,
+		`testdata/changelog.lint.md:19: non-standard section name "Known Issues" (expected one of: Added, Changed, Deprecated, Removed, Fixed, Security) (section-name)`,
+		"testdata/changelog.lint.md:22: release date 29.01.2020 of version 1.4.0 is not in the YYYY-MM-DD format (date-format)",
+		"testdata/changelog.lint.md:26: version 1.6.0 is listed after the older version 1.4.0 on line 22 (version-order)",
+		"testdata/changelog.lint.md:30: version 1.3.0 has no release date (missing-date)",
+		`testdata/changelog.lint.md:34: no version found in heading "Version one" (version-heading)`,
+		"testdata/changelog.lint.md:38: release date 03/04/20 of version 1.2.0 could be read day or month first (ambiguous-date)",
+		"testdata/changelog.lint.md:42: release date 2019-02-30 of version 1.1.0 is not a valid date (invalid-date)",
+		"testdata/changelog.lint.md:46: version 1.3.0 is already listed on line 30 (duplicate-version)",
+	}, actual)
+}
+
+func TestLintValidChangelog(t *testing.T) {
+	markdown := `# Changelog
+
+## [Unreleased]
+
+## [1.1.0] - 2020-02-01
+### Added
+- Feature
+  - Detail
+
+### Fixed
+- Fix
+
+## 1.0.0+suite.2 - 2020-01-02
+### Fixed
+- Rebuilt release
+
+## 1.0.0+suite.1 - 2020-01-01
+### Added
+- Initial release
+
+[Unreleased]: https://github.com/cyberark/conjur/compare/v1.1.0...HEAD
+[1.1.0]: https://github.com/cyberark/conjur/compare/v1.0.0...v1.1.0
+`
+
+	assert.Empty(t, Lint("CHANGELOG.md", markdown, nil))
+}
+
+func TestLintConfiguredSectionAliases(t *testing.T) {
+	markdown := `# Changelog
+
+## [1.1.0] - 2020-02-01
+### Known Issues
+- Something
+
+### Breaking
+- Removed something
+
+### Other
+- Anything else
+
+[1.1.0]: https://github.com/cyberark/conjur/compare/v1.0.0...v1.1.0
+`
+
+	aliases := NewSectionAliases(map[string]string{
+		"Known Issues": "Known Issues",
+		"Breaking":     "Removed",
+	})
+
+	var actual []string
+	for _, diagnostic := range Lint("CHANGELOG.md", markdown, aliases) {
+		actual = append(actual, diagnostic.String())
+	}
+
+	assert.Equal(t, []string{
+		`CHANGELOG.md:7: section "Breaking" should be named "Removed" (section-name)`,
+		`CHANGELOG.md:10: non-standard section name "Other" (expected one of: Added, Changed, Deprecated, Removed, Fixed, Security, Known Issues) (section-name)`,
+	}, actual)
+}
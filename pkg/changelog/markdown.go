@@ -0,0 +1,134 @@
+package changelog
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// inlineMarkdown serializes a node back to markdown. Only inline markup is
+// kept; the delimiters used in the source (e.g. `_` or `*` for emphasis) are
+// normalized and characters that were escaped are escaped again. Reference
+// links, e.g. `[text][id]`, and autolinks, e.g. `<https://...>`, are written
+// as inline links unless `keepLinkStyle` is set, since the definitions of
+// reference links aren't part of the node. Autolinks are then written as bare
+// URLs, which the parser links again.
+func inlineMarkdown(node ast.Node, keepLinkStyle bool) string {
+	switch n := node.(type) {
+	case *ast.Text:
+		return escapeMarkdown(string(n.Literal))
+	case *ast.Code:
+		return codeSpan(string(n.Literal))
+	case *ast.Softbreak, *ast.Hardbreak:
+		return "\n"
+	case *ast.Emph:
+		return "*" + inlineChildrenMarkdown(n, keepLinkStyle) + "*"
+	case *ast.Strong:
+		return "**" + inlineChildrenMarkdown(n, keepLinkStyle) + "**"
+	case *ast.Del:
+		return "~~" + inlineChildrenMarkdown(n, keepLinkStyle) + "~~"
+	case *ast.Link:
+		text := inlineChildrenMarkdown(n, keepLinkStyle)
+		switch {
+		case keepLinkStyle && len(n.DeferredID) > 0:
+			return "[" + text + "][" + referenceID(text, string(n.DeferredID)) + "]"
+		case keepLinkStyle && len(n.Title) == 0 && leafText(n) == string(n.Destination):
+			return string(n.Destination)
+		}
+		return "[" + text + "](" + linkTarget(n.Destination, n.Title) + ")"
+	case *ast.Image:
+		return "![" + inlineChildrenMarkdown(n, keepLinkStyle) + "](" + linkTarget(n.Destination, n.Title) + ")"
+	}
+
+	if leaf := node.AsLeaf(); leaf != nil {
+		return string(leaf.Literal)
+	}
+
+	return inlineChildrenMarkdown(node, keepLinkStyle)
+}
+
+// inlineChildrenMarkdown serializes the children of a node
+func inlineChildrenMarkdown(node ast.Node, keepLinkStyle bool) string {
+	markdown := ""
+	for _, child := range node.GetChildren() {
+		markdown += inlineMarkdown(child, keepLinkStyle)
+	}
+
+	return markdown
+}
+
+// referenceID returns the label of a reference link, which is left out, as in
+// `[text][]`, if it's the text of the link
+func referenceID(text string, id string) string {
+	if strings.EqualFold(text, id) {
+		return ""
+	}
+
+	return id
+}
+
+// codeSpan wraps code in enough backticks to hold any backticks it contains
+func codeSpan(code string) string {
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	// Padding keeps a leading or trailing backtick from merging with the fence
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+
+	return fence + code + fence
+}
+
+// escapeMarkdown backslash-escapes the characters of text that would otherwise
+// be read as markup. The parser splits text where it unescapes a character,
+// so characters at either end of the text are escaped unless they can't be
+// markup at all, e.g. an `_` within a word.
+func escapeMarkdown(text string) string {
+	runes := []rune(text)
+	isWordRune := func(i int) bool {
+		return i >= 0 && i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]))
+	}
+	isRune := func(i int, r rune) bool {
+		return i >= 0 && i < len(runes) && runes[i] == r
+	}
+
+	var escaped strings.Builder
+	for i, r := range runes {
+		escape := false
+		switch r {
+		case '\\', '`', '*', '[', ']':
+			escape = true
+		case '_':
+			escape = !isWordRune(i-1) || !isWordRune(i+1)
+		case '~':
+			escape = len(runes) == 1 || isRune(i-1, '~') || isRune(i+1, '~')
+		case '<':
+			escape = i == len(runes)-1 || unicode.IsLetter(runes[i+1]) || strings.ContainsRune("/!?", runes[i+1])
+		}
+
+		if escape {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+
+	return escaped.String()
+}
+
+// linkTarget writes the destination of a link, and its title if it has one
+func linkTarget(destination []byte, title []byte) string {
+	target := string(destination)
+	if strings.ContainsAny(target, " ()") {
+		target = "<" + target + ">"
+	}
+
+	if len(title) == 0 {
+		return target
+	}
+
+	return target + ` "` + strings.ReplaceAll(string(title), `"`, `\"`) + `"`
+}
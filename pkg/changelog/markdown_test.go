@@ -0,0 +1,65 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown/ast"
+	markdownparser "github.com/gomarkdown/markdown/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineMarkdown(t *testing.T) {
+	testData := []struct {
+		description string
+		markdown    string
+		expected    string
+	}{
+		{
+			description: "markup",
+			markdown:    "**foo** _bar_ ~~baz~~ `qux`",
+			expected:    "**foo** *bar* ~~baz~~ `qux`",
+		},
+		{
+			description: "escapes",
+			markdown:    `\*literal\* \_literal\_ \[literal\] \` + "`" + `literal\` + "`" + ` back\\slash \<b> \~\~literal\~\~`,
+			expected:    `\*literal\* \_literal\_ \[literal\] \` + "`" + `literal\` + "`" + ` back\\slash \<b> \~\~literal\~\~`,
+		},
+		{
+			description: "characters that can't be markup",
+			markdown:    "snake_case 1 < 2 ~1.2 & co",
+			expected:    "snake_case 1 < 2 ~1.2 & co",
+		},
+		{
+			description: "links",
+			markdown:    "[foo](https://example.com) [bar][1] <https://example.org>\n\n[1]: https://example.net",
+			expected:    "[foo](https://example.com) [bar](https://example.net) [https://example.org](https://example.org)",
+		},
+		{
+			description: "titled links",
+			markdown:    `[foo](https://example.com "The title") ![bar](bar.png 'Image title')`,
+			expected:    `[foo](https://example.com "The title") ![bar](bar.png "Image title")`,
+		},
+	}
+
+	for _, td := range testData {
+		t.Run(td.description, func(t *testing.T) {
+			paragraph := markdownparser.New().Parse([]byte(td.markdown)).GetChildren()[0]
+			if !assert.IsType(t, &ast.Paragraph{}, paragraph) {
+				return
+			}
+
+			actual := inlineMarkdown(paragraph, false)
+			assert.Equal(t, td.expected, actual)
+
+			// Serializing is stable
+			reparsed := markdownparser.New().Parse([]byte(actual)).GetChildren()[0]
+			assert.Equal(t, actual, inlineMarkdown(reparsed, false))
+		})
+	}
+
+	t.Run("keeping the link style", func(t *testing.T) {
+		paragraph := markdownparser.New().Parse([]byte("[foo](https://example.com) [bar][1] [Baz][] [qux] <https://example.org>\n\n[1]: https://example.net\n[baz]: https://example.net/baz\n[qux]: https://example.net/qux")).GetChildren()[0]
+		// The parser doesn't record the label of shortcut references
+		assert.Equal(t, "[foo](https://example.com) [bar][1] [Baz][] [qux](https://example.net/qux) https://example.org", inlineMarkdown(paragraph, true))
+	})
+}
@@ -1,10 +1,11 @@
 package changelog
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/gomarkdown/markdown/ast"
 	markdownparser "github.com/gomarkdown/markdown/parser"
@@ -12,10 +13,35 @@ import (
 
 // VersionChangelog contains a full changelog for a version of a repo
 type VersionChangelog struct {
-	Repo     string
-	Version  string
-	Date     string
-	Sections map[string][]string
+	Repo    string
+	Version string
+	Date    string
+	// ParsedDate is the Date parsed by ParseDate, or zero if the date is
+	// missing, ambiguous or invalid
+	ParsedDate time.Time
+	Sections   []Section
+	// URL is the link of the version heading, usually to a compare view of
+	// the changes, e.g. from a `[1.2.3]: https://...` link reference definition
+	URL string
+	// Unreleased is set for the changes that haven't been released yet, whose
+	// Version is UnreleasedVersion
+	Unreleased bool
+	// FromReleaseBody is set if the changes come from the description of the
+	// release rather than the CHANGELOG, see ParseReleaseBody
+	FromReleaseBody bool
+}
+
+// UnreleasedVersion is the Version of the unreleased changes
+const UnreleasedVersion = "Unreleased"
+
+// ReleaseURL links to the version: its URL if the changelog defines one, or
+// else its GitHub release
+func (c *VersionChangelog) ReleaseURL() string {
+	if c.URL != "" {
+		return c.URL
+	}
+
+	return fmt.Sprintf("https://github.com/%s/releases/tag/v%s", c.Repo, c.Version)
 }
 
 // semantic versioning pattern
@@ -26,7 +52,186 @@ const semverRgx = `\[?v?([\w\d.-]+\.[\w\d.-]+[a-zA-Z0-9])\]?`
 // YYYY-MM-DD (or DD.MM.YYYY, D/M/YY, etc.)
 const dateRgx = `.*[ ](\d\d?\d?\d?[-/.]\d\d?[-/.]\d\d?\d?\d?).*`
 
+// Compiled once since Parse matches them against every version heading
+var semverRegexp = regexp.MustCompile(semverRgx)
+var dateRegexp = regexp.MustCompile(dateRgx)
+
+// Matches the heading of the unreleased changes, e.g. `[Unreleased]`
+var unreleasedRegexp = regexp.MustCompile(`(?i)^\s*\[?unreleased\b`)
+
+// Matches the fences of fenced code blocks
+var codeFenceRegexp = regexp.MustCompile("^\\s*(```|~~~)")
+
+// Matches `[label]: destination`
+var linkReferenceDefinitionRegexp = regexp.MustCompile(`^\s{0,3}\[([^\]]+)\]:\s*<?([^\s>]+)`)
+
+// linkReferenceDefinitions returns the destinations of the link reference
+// definitions of a changelog, keyed by lower-case label
+func linkReferenceDefinitions(lines []string) map[string]string {
+	definitions := map[string]string{}
+
+	insideCodeFence := false
+	for _, line := range lines {
+		if codeFenceRegexp.MatchString(line) {
+			insideCodeFence = !insideCodeFence
+			continue
+		}
+
+		match := linkReferenceDefinitionRegexp.FindStringSubmatch(line)
+		if insideCodeFence || match == nil {
+			continue
+		}
+
+		// The first definition of a label wins
+		label := strings.ToLower(match[1])
+		if _, ok := definitions[label]; !ok {
+			definitions[label] = match[2]
+		}
+	}
+
+	return definitions
+}
+
+// resolveURL looks the version up in the link reference definitions if its
+// heading isn't a link, e.g. because the definition is for `[v1.2.3]` while the
+// heading is `## 1.2.3`
+func (c *VersionChangelog) resolveURL(definitions map[string]string) {
+	if c.URL != "" {
+		return
+	}
+
+	for _, label := range []string{c.Version, "v" + c.Version} {
+		if url, ok := definitions[strings.ToLower(label)]; ok {
+			c.URL = url
+			return
+		}
+	}
+}
+
+// parseWithLines parses a changelog and returns the (1-based) source line of
+// every list item of the lists at its top level, e.g. not those in a block
+// quote. The parser doesn't record positions, so it has to work them out
+// itself: the block hook sees where each top-level block starts, since it gets
+// the rest of the input, and each list is parsed again a line at a time to see
+// which line adds each of its items, nested ones included.
+func parseWithLines(changelog []byte) (ast.Node, map[*ast.ListItem]int) {
+	type block struct {
+		offset int
+		// index is where the block goes among the children of the document
+		index int
+	}
+
+	var blocks []block
+	parser := markdownparser.New()
+	parser.Opts.ParserHook = func(data []byte) (ast.Node, []byte, int) {
+		// Nested blocks are parsed from copies of the input
+		offset := len(changelog) - len(data)
+		if len(data) > 0 && offset >= 0 && &changelog[offset] == &data[0] {
+			blocks = append(blocks, block{offset: offset, index: len(parser.Doc.GetChildren())})
+		}
+
+		return nil, nil, 0
+	}
+	root := parser.Parse(changelog)
+
+	lines := map[*ast.ListItem]int{}
+	children := root.GetChildren()
+	for i, start := range blocks {
+		if start.index >= len(children) {
+			break
+		}
+
+		list, ok := children[start.index].(*ast.List)
+		end := len(changelog)
+		if i+1 < len(blocks) {
+			end = blocks[i+1].offset
+		}
+
+		// Blank lines go by without adding a block
+		if !ok || (i+1 < len(blocks) && blocks[i+1].index == start.index) {
+			continue
+		}
+
+		items := listItems(list)
+		firstLine := bytes.Count(changelog[:start.offset], []byte("\n")) + 1
+		for j, offset := range listItemOffsets(changelog[start.offset:end], len(items)) {
+			lines[items[j]] = firstLine + offset
+		}
+	}
+
+	return root, lines
+}
+
+// listItemOffsets returns the line, counted from 0, of each of the first
+// `count` list items of the source of a list, found by parsing ever more of it
+func listItemOffsets(source []byte, count int) []int {
+	var offsets []int
+	end := 0
+	for line, text := range bytes.SplitAfter(source, []byte("\n")) {
+		if len(offsets) >= count {
+			break
+		}
+		end += len(text)
+
+		// A line can start several items, e.g. `- - nested`
+		parsed := len(listItems(markdownparser.New().Parse(source[:end])))
+		for len(offsets) < parsed && len(offsets) < count {
+			offsets = append(offsets, line)
+		}
+	}
+
+	return offsets
+}
+
+// listItems returns the list items within a node in order of appearance
+func listItems(node ast.Node) []*ast.ListItem {
+	var items []*ast.ListItem
+	ast.WalkFunc(node, func(node ast.Node, entering bool) ast.WalkStatus {
+		if item, ok := node.(*ast.ListItem); ok && entering {
+			items = append(items, item)
+		}
+
+		return ast.GoToNext
+	})
+
+	return items
+}
+
+// listItemEntry builds the entry of a list item, with its nested list items as
+// children. Its Markdown keeps reference links and has the paragraphs of the
+// item separated by blank lines, while its Text has them on consecutive lines.
+// Items whose source line isn't known, see parseWithLines, get none.
+func listItemEntry(repo string, item *ast.ListItem, lines map[*ast.ListItem]int) Entry {
+	var paragraphs []string
+	var sourceParagraphs []string
+	var lists []*ast.List
+	for _, child := range item.GetChildren() {
+		if list, ok := child.(*ast.List); ok {
+			lists = append(lists, list)
+			continue
+		}
+
+		paragraphs = append(paragraphs, inlineMarkdown(child, false))
+		sourceParagraphs = append(sourceParagraphs, inlineMarkdown(child, true))
+	}
+
+	entry := NewEntry(repo, strings.Join(paragraphs, "\n"))
+	entry.Markdown = strings.Join(sourceParagraphs, "\n\n")
+	entry.Line = lines[item]
+
+	for _, list := range lists {
+		for _, nested := range list.GetChildren() {
+			if nestedItem, ok := nested.(*ast.ListItem); ok {
+				entry.Children = append(entry.Children, listItemEntry(repo, nestedItem, lines))
+			}
+		}
+	}
+
+	return entry
+}
+
 // Parse extracts and returns a slice of changelogs, one for each version.
+// Unreleased changes are returned as a version too, see UnreleasedVersion.
 // Parse assumes a changelog in the [keep a changelog](https://keepachangelog.com/) format:
 //
 // # changelog title
@@ -63,46 +268,40 @@ const dateRgx = `.*[ ](\d\d?\d?\d?[-/.]\d\d?[-/.]\d\d?\d?\d?).*`
 //
 // [a.b.c]: http://altavista.com
 func Parse(repo string, changelog string) ([]*VersionChangelog, error) {
-	scanner := bufio.NewScanner(strings.NewReader(changelog))
-
 	var versionChangelog *VersionChangelog
 	var changelogs []*VersionChangelog
 
-	parser := markdownparser.New()
-	rootNode := parser.Parse([]byte(changelog))
+	lines := strings.Split(strings.ReplaceAll(changelog, "\r\n", "\n"), "\n")
+	definitions := linkReferenceDefinitions(lines)
+
+	rootNode, listItemLines := parseWithLines([]byte(changelog))
 
 	// state-machine state
 	var insideVersion = false
 	var insideSection = false
-	var insideListItem = false
 
 	// Buffers used to extract values spanning multiple AST nodes
 	var versionBuffer = ""
 	var sectionBuffer = ""
-	var listItemBuffer = ""
 
 	// Extract changelog versions
 	ast.WalkFunc(rootNode, func(node ast.Node, entering bool) ast.WalkStatus {
 		switch n := node.(type) {
-		// Handle list-item found anywhere along version > section > list-item
+		// Handle list-item found anywhere along version > section > list-item.
+		// Nested list items are handled as part of their parent.
 		case *ast.ListItem:
-			insideListItem = entering
+			if !entering {
+				break
+			}
 
-			if entering {
-				// On entering list-item node under version section, reset list-item buffer
-				listItemBuffer = ""
-			} else {
-				// On exiting list-item node under version section, populate changelog with
-				// list-item by reading list-item buffer
-				if _, ok := versionChangelog.Sections[sectionBuffer]; !ok {
-					versionChangelog.Sections[sectionBuffer] = []string{}
-				}
+			entry := listItemEntry(repo, n, listItemLines)
 
-				versionChangelog.Sections[sectionBuffer] = append(
-					versionChangelog.Sections[sectionBuffer],
-					string(listItemBuffer),
-				)
+			// List items before the first version aren't changes
+			if versionChangelog != nil {
+				versionChangelog.addEntry(sectionBuffer, entry)
 			}
+
+			return ast.SkipChildren
 		// Handle text
 		case *ast.Text:
 			switch {
@@ -110,10 +309,8 @@ func Parse(repo string, changelog string) ([]*VersionChangelog, error) {
 				versionBuffer += string(n.Literal)
 			case insideSection:
 				sectionBuffer += string(n.Literal)
-			case insideListItem:
-				listItemBuffer += string(n.Literal)
 			}
-		// Handle link found anywhere along version > section > list-item
+		// Handle link found in a version or section heading
 		case *ast.Link:
 			txt := ""
 			if entering {
@@ -127,13 +324,13 @@ func Parse(repo string, changelog string) ([]*VersionChangelog, error) {
 			switch {
 			case insideSection:
 				sectionBuffer += txt
-			case insideListItem:
-				listItemBuffer += txt
 			case insideVersion:
-				// Do nothing.
 				// This avoids having destination as part of the versionBuffer. This is
 				// because the link regex doesn't expect the link destination to be
-				// present.
+				// present. Reference links like `[1.2.3]` are resolved by the parser.
+				if entering && versionChangelog.URL == "" {
+					versionChangelog.URL = string(n.Destination)
+				}
 			}
 		// Handle heading
 		case *ast.Heading:
@@ -145,29 +342,41 @@ func Parse(repo string, changelog string) ([]*VersionChangelog, error) {
 				if entering {
 					// On entering version header node, initialise changelog
 					versionChangelog = &VersionChangelog{
-						Repo:     repo,
-						Sections: map[string][]string{},
+						Repo: repo,
 					}
 					versionBuffer = ""
 
+					// Entries before the first section of a version belong to none
+					sectionBuffer = ""
+
 					changelogs = append(changelogs, versionChangelog)
 
 				} else {
 					// On exiting version header node, populate changelog
 
-					// Extract version
-					version := regexp.MustCompile(semverRgx).FindStringSubmatch(versionBuffer)
+					// Extract version. The pending changes are kept as a version of
+					// their own.
+					if unreleasedRegexp.MatchString(versionBuffer) {
+						versionChangelog.Version = UnreleasedVersion
+						versionChangelog.Unreleased = true
+						versionChangelog.resolveURL(definitions)
+						break
+					}
+
+					version := semverRegexp.FindStringSubmatch(versionBuffer)
 					if len(version) == 0 {
 						break
 					}
 					versionChangelog.Version = string(version[1])
+					versionChangelog.resolveURL(definitions)
 
 					// Extract date
-					date := regexp.MustCompile(dateRgx).FindStringSubmatch(versionBuffer)
+					date := dateRegexp.FindStringSubmatch(versionBuffer)
 					if len(date) == 0 {
 						break
 					}
 					versionChangelog.Date = string(date[1])
+					versionChangelog.ParsedDate, _ = ParseDate(versionChangelog.Date)
 				}
 
 			// Handle section under version
@@ -187,8 +396,6 @@ func Parse(repo string, changelog string) ([]*VersionChangelog, error) {
 					versionBuffer += string(leafNode.Literal)
 				case insideSection:
 					sectionBuffer += string(leafNode.Literal)
-				case insideListItem:
-					listItemBuffer += string(leafNode.Literal)
 				}
 			}
 		}
@@ -207,7 +414,5 @@ func Parse(repo string, changelog string) ([]*VersionChangelog, error) {
 	}
 	changelogs = changelogs[:n]
 
-	err := scanner.Err()
-
-	return changelogs, err
+	return changelogs, nil
 }
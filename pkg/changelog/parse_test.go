@@ -3,7 +3,9 @@ package changelog
 import (
 	"fmt"
 	"io/ioutil"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,17 +26,35 @@ func TestParseSimpleChangelog(t *testing.T) {
 	}
 
 	assert.Equal(t, changelogs[0], &VersionChangelog{
-		Repo:    "test-repo",
-		Version: "1.5.0",
-		Date:    "2020-01-29",
-		Sections: map[string][]string{
-			"Added": {
-				"add 1",
-				"cyberark/conjur@1.4.4: Bumped toolset from 3.12.0 to 3.12.2",
+		Repo:       "test-repo",
+		Version:    "Unreleased",
+		Unreleased: true,
+	})
+
+	assert.Equal(t, changelogs[1], &VersionChangelog{
+		Repo:       "test-repo",
+		Version:    "1.5.0",
+		Date:       "2020-01-29",
+		ParsedDate: time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC),
+		Sections: []Section{
+			{
+				Name: "Added",
+				Entries: []Entry{
+					{Repo: "test-repo", Text: "add 1", Markdown: "add 1", Line: 8},
+					{
+						Repo:     "test-repo",
+						Text:     "`cyberark/conjur@1.4.4`: Bumped `toolset` from 3.12.0 to 3.12.2",
+						Markdown: "`cyberark/conjur@1.4.4`: Bumped `toolset` from 3.12.0 to 3.12.2",
+						Line:     9,
+					},
+				},
 			},
-			"Changed": {
-				"change 1",
-				"change 2",
+			{
+				Name: "Changed",
+				Entries: []Entry{
+					{Repo: "test-repo", Text: "change 1", Markdown: "change 1", Line: 12},
+					{Repo: "test-repo", Text: "change 2", Markdown: "change 2", Line: 13},
+				},
 			},
 		},
 	})
@@ -46,17 +66,258 @@ func TestComplexChangelog(t *testing.T) {
 		return
 	}
 
-	assert.Equal(t, changelogs[0], &VersionChangelog{
-		Repo:    "test-repo",
-		Version: "1.4.6",
-		Date:    "2020-01-21",
-		Sections: map[string][]string{
-			"Changed": {
-				`K8s hosts' resource restrictions is extracted from annotations or id. If it is
+	assert.Equal(t, changelogs[1], &VersionChangelog{
+		Repo:       "test-repo",
+		Version:    "1.4.6",
+		Date:       "2020-01-21",
+		ParsedDate: time.Date(2020, time.January, 21, 0, 0, 0, 0, time.UTC),
+		URL:        "https://github.com/cyberark/conjur/compare/v1.4.5...v1.4.6",
+		Sections: []Section{
+			{
+				Name: "Changed",
+				Entries: []Entry{
+					{
+						Repo: "test-repo",
+						Text: `K8s hosts' resource restrictions is extracted from annotations or id. If it is
 defined in annotations it will taken from there and if not, it will be taken
 from the id.`,
-				"Another change ABC!@#$%",
+						Markdown: `K8s hosts' resource restrictions is extracted from annotations or id. If it is
+defined in annotations it will taken from there and if not, it will be taken
+from the id.`,
+						Line: 16,
+					},
+					{
+						Repo:     "test-repo",
+						Text:     "Another change ABC!@#$%",
+						Markdown: "Another change ABC!@#$%",
+						Line:     19,
+					},
+				},
 			},
 		},
 	})
 }
+
+func TestParseEntryMetadata(t *testing.T) {
+	changelogs, err := parseChangelog("changelog.entries.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if !assert.Len(t, changelogs, 1) {
+		return
+	}
+
+	assert.Equal(t, []Section{
+		{
+			Name: "Changed",
+			Entries: []Entry{
+				{
+					Repo:     "test-repo",
+					Text:     "**BREAKING**: Dropped support for v4 policies (#123, cyberark/conjur#45)",
+					Markdown: "**BREAKING**: Dropped support for v4 policies (#123, cyberark/conjur#45)",
+					Line:     13,
+					References: []Reference{
+						{Repo: "test-repo", Number: 123},
+						{Repo: "cyberark/conjur", Number: 45},
+					},
+					Breaking: true,
+				},
+			},
+		},
+		{
+			Name: "Security",
+			Entries: []Entry{
+				{
+					Repo:     "test-repo",
+					Text:     "Fixed CVE-2020-1234, see\n[the advisory](https://github.com/test-repo/advisories/1)\nand cve-2020-5678",
+					Markdown: "Fixed CVE-2020-1234, see\n[the advisory](https://github.com/test-repo/advisories/1)\nand cve-2020-5678",
+					Line:     16,
+					URLs:     []string{"https://github.com/test-repo/advisories/1"},
+					CVEs:     []string{"CVE-2020-1234", "CVE-2020-5678"},
+				},
+			},
+		},
+	}, changelogs[0].Sections)
+}
+
+func TestParseNestedEntries(t *testing.T) {
+	changelogs, err := parseChangelog("changelog.nested.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	entries := changelogs[0].Entries("Changed")
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+
+	assert.Equal(t, "Upgraded **all** dependencies:", entries[0].Text)
+	assert.Equal(t, 6, entries[0].Line)
+	if assert.Len(t, entries[0].Children, 2) {
+		assert.Equal(t, "`puma` to 4.3.3", entries[0].Children[0].Text)
+		assert.Equal(t, 7, entries[0].Children[0].Line)
+
+		rack := entries[0].Children[1]
+		assert.Equal(t, "*rack* to 2.1.2, fixing", rack.Text)
+		assert.Equal(t, 8, rack.Line)
+		if assert.Len(t, rack.Children, 2) {
+			assert.Equal(t, "~~CVE-2020-8161~~ and", rack.Children[0].Text)
+			assert.Equal(t, []string{"CVE-2020-8184"}, rack.Children[1].CVEs)
+			assert.Equal(t, 10, rack.Children[1].Line)
+		}
+	}
+
+	assert.Equal(t, "Renamed the `` `conjur` `` binary, see [the docs](https://docs.conjur.org)", entries[1].Text)
+	assert.Equal(t, 11, entries[1].Line)
+	assert.Empty(t, entries[1].Children)
+
+	assert.Equal(t, `Upgraded **all** dependencies:
+  - `+"`puma`"+` to 4.3.3
+  - *rack* to 2.1.2, fixing
+    - ~~CVE-2020-8161~~ and
+    - CVE-2020-8184`, entries[0].Text+entries[0].ChildrenMarkdown("  "))
+}
+
+func TestParseLongLines(t *testing.T) {
+	long := strings.Repeat("a", 70000)
+	changelogs, err := Parse("test-repo", "# Changelog\n\n```\n"+long+"\n```\n\n## 1.0.0\n\n### Added\n- first\n- second\n")
+	if !assert.NoError(t, err) || !assert.Len(t, changelogs, 1) {
+		return
+	}
+
+	added := changelogs[0].Entries("Added")
+	if assert.Len(t, added, 2) {
+		assert.Equal(t, 10, added[0].Line)
+		assert.Equal(t, 11, added[1].Line)
+	}
+}
+
+func TestParseVersionURLs(t *testing.T) {
+	changelogs, err := Parse("cyberark/conjur", `# Changelog
+
+## [Unreleased]
+
+## [1.2.3] - 2020-03-01
+
+## [1.2.2](https://example.com/1.2.2) - 2020-02-01
+
+## 1.2.1 - 2020-01-01
+
+## 1.2.0 - 2019-12-01
+
+`+"```"+`
+[1.2.0]: https://example.com/not-a-definition
+`+"```"+`
+
+[Unreleased]: https://github.com/cyberark/conjur/compare/v1.2.3...HEAD
+[1.2.3]: https://github.com/cyberark/conjur/compare/v1.2.2...v1.2.3
+[v1.2.1]: https://github.com/cyberark/conjur/compare/v1.2.0...v1.2.1
+`)
+	if !assert.NoError(t, err) || !assert.Len(t, changelogs, 5) {
+		return
+	}
+
+	assert.Equal(t, "https://github.com/cyberark/conjur/compare/v1.2.3...HEAD", changelogs[0].URL)
+	assert.Equal(t, "https://github.com/cyberark/conjur/compare/v1.2.2...v1.2.3", changelogs[1].ReleaseURL())
+	assert.Equal(t, "https://example.com/1.2.2", changelogs[2].ReleaseURL())
+	assert.Equal(t, "https://github.com/cyberark/conjur/compare/v1.2.0...v1.2.1", changelogs[3].ReleaseURL())
+
+	// Versions without a definition link to their GitHub release
+	assert.Empty(t, changelogs[4].URL)
+	assert.Equal(t, "https://github.com/cyberark/conjur/releases/tag/v1.2.0", changelogs[4].ReleaseURL())
+}
+
+func TestParseListItemsAmongOtherBlocks(t *testing.T) {
+	changelogs, err := parseChangelog("changelog.blocks.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, changelogs, 1) {
+		return
+	}
+
+	t.Run("skips list items in comments and code", func(t *testing.T) {
+		added := changelogs[0].Entries("Added")
+		if !assert.Len(t, added, 4) {
+			return
+		}
+
+		assert.Equal(t, "First entry", added[0].Markdown)
+		assert.Equal(t, 11, added[0].Line)
+		assert.Equal(t, "Second entry", added[1].Markdown)
+		assert.Equal(t, 12, added[1].Line)
+
+		fixed := changelogs[0].Entries("Fixed")
+		if assert.Len(t, fixed, 2) {
+			assert.Equal(t, "Fixed entry", fixed[0].Markdown)
+			assert.Equal(t, 28, fixed[0].Line)
+		}
+	})
+
+	t.Run("keeps every paragraph of loose list items", func(t *testing.T) {
+		added := changelogs[0].Entries("Added")
+		if !assert.Len(t, added, 4) {
+			return
+		}
+
+		assert.Equal(t, "Loose entry\nWith a second paragraph", added[2].Text)
+		assert.Equal(t, "Loose entry\n\nWith a second paragraph", added[2].Markdown)
+		assert.Equal(t, 18, added[2].Line)
+
+		assert.Equal(t, "Last entry\nwrapped onto a second line", added[3].Markdown)
+		assert.Equal(t, 21, added[3].Line)
+	})
+
+	t.Run("list items in block quotes have no source line", func(t *testing.T) {
+		fixed := changelogs[0].Entries("Fixed")
+		if !assert.Len(t, fixed, 2) {
+			return
+		}
+
+		// List items in block quotes are parsed but their lines are skipped
+		assert.Equal(t, "Quoted entry", fixed[1].Text)
+		assert.Equal(t, "Quoted entry", fixed[1].Markdown)
+		assert.Equal(t, 0, fixed[1].Line)
+	})
+}
+
+func TestParseListItemLines(t *testing.T) {
+	changelogs, err := Parse("test-repo", `# Changelog
+
+## [1.0.0] - 2020-01-01
+
+### Added
+- <!-- a comment first --> Commented entry
+- &copy; \*Escaped\* entry, see [the docs][docs]
+1. Ordered entry
+   - Nested entry
+
+         With a second paragraph
+   - Another nested entry
+
+[docs]: https://docs.conjur.org
+`)
+	if !assert.NoError(t, err) || !assert.Len(t, changelogs, 1) {
+		return
+	}
+
+	added := changelogs[0].Entries("Added")
+	if !assert.Len(t, added, 3) {
+		return
+	}
+
+	assert.Equal(t, 6, added[0].Line)
+	assert.Equal(t, "<!-- a comment first --> Commented entry", added[0].Markdown)
+
+	assert.Equal(t, 7, added[1].Line)
+	assert.Equal(t, "&copy; \\*Escaped\\* entry, see [the docs](https://docs.conjur.org)", added[1].Text)
+	assert.Equal(t, "&copy; \\*Escaped\\* entry, see [the docs][docs]", added[1].Markdown)
+
+	assert.Equal(t, 8, added[2].Line)
+	if assert.Len(t, added[2].Children, 2) {
+		assert.Equal(t, 9, added[2].Children[0].Line)
+		assert.Equal(t, "Nested entry\n\nWith a second paragraph", added[2].Children[0].Markdown)
+		assert.Equal(t, 12, added[2].Children[1].Line)
+	}
+}
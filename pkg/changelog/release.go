@@ -0,0 +1,59 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Matches headings of any level
+var anyHeadingRegexp = regexp.MustCompile(`^\s{0,3}#{1,6}\s+(.*?)[\s#]*$`)
+
+// ParseReleaseBody parses the description of a release, e.g. the body of a
+// GitHub release, as the changelog of its version. Descriptions are changelog
+// fragments without a version heading, so every heading is read as a section
+// heading whatever its level, e.g. `## Bug Fixes`. It returns nil if the
+// description has no entries.
+func ParseReleaseBody(repo string, version string, body string) (*VersionChangelog, error) {
+	// The fragment goes under a version heading and a blank line
+	const headingLines = 2
+
+	var fragment strings.Builder
+	fragment.WriteString("## [" + strings.TrimPrefix(version, "v") + "]\n\n")
+
+	insideCodeFence := false
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		if codeFenceRegexp.MatchString(line) {
+			insideCodeFence = !insideCodeFence
+		} else if match := anyHeadingRegexp.FindStringSubmatch(line); !insideCodeFence && match != nil {
+			line = "### " + match[1]
+		}
+
+		fragment.WriteString(line + "\n")
+	}
+
+	changelogs, err := Parse(repo, fragment.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(changelogs) == 0 || len(changelogs[0].Sections) == 0 {
+		return nil, nil
+	}
+
+	versionChangelog := changelogs[0]
+	versionChangelog.FromReleaseBody = true
+	for i := range versionChangelog.Sections {
+		shiftLines(versionChangelog.Sections[i].Entries, -headingLines)
+	}
+
+	return versionChangelog, nil
+}
+
+// shiftLines moves the source lines of entries and their children
+func shiftLines(entries []Entry, offset int) {
+	for i := range entries {
+		if entries[i].Line > 0 {
+			entries[i].Line += offset
+		}
+		shiftLines(entries[i].Children, offset)
+	}
+}
@@ -0,0 +1,55 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReleaseBody(t *testing.T) {
+	body := "## What's Changed\r\n" +
+		"* Added a `--json` flag by @someone in https://github.com/cyberark/conjur/pull/12\r\n" +
+		"\r\n" +
+		"# Bug Fixes\r\n" +
+		"- Fixed a crash\r\n" +
+		"  - when adding users\r\n" +
+		"\r\n" +
+		"```\r\n" +
+		"## not a heading\r\n" +
+		"```\r\n" +
+		"\r\n" +
+		"**Full Changelog**: https://github.com/cyberark/conjur/compare/v1.2.2...v1.2.3\r\n"
+
+	versionChangelog, err := ParseReleaseBody("cyberark/conjur", "v1.2.3", body)
+	if !assert.NoError(t, err) || !assert.NotNil(t, versionChangelog) {
+		return
+	}
+
+	assert.Equal(t, "cyberark/conjur", versionChangelog.Repo)
+	assert.Equal(t, "1.2.3", versionChangelog.Version)
+	assert.True(t, versionChangelog.FromReleaseBody)
+	assert.Equal(t, []string{"What's Changed", "Bug Fixes"}, sectionNames(versionChangelog.Sections))
+
+	added := versionChangelog.Entries("What's Changed")
+	if assert.Len(t, added, 1) {
+		assert.Equal(t, 2, added[0].Line)
+		assert.Equal(t, []string{"https://github.com/cyberark/conjur/pull/12"}, added[0].URLs)
+	}
+
+	fixed := versionChangelog.Entries("Bug Fixes")
+	if assert.Len(t, fixed, 1) {
+		assert.Equal(t, "Fixed a crash", fixed[0].Text)
+		assert.Equal(t, 5, fixed[0].Line)
+		if assert.Len(t, fixed[0].Children, 1) {
+			assert.Equal(t, 6, fixed[0].Children[0].Line)
+		}
+	}
+}
+
+func TestParseReleaseBodyWithoutEntries(t *testing.T) {
+	for _, body := range []string{"", "Just a paragraph about the release."} {
+		versionChangelog, err := ParseReleaseBody("cyberark/conjur", "v1.2.3", body)
+		assert.NoError(t, err)
+		assert.Nil(t, versionChangelog, body)
+	}
+}
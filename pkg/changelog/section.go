@@ -0,0 +1,234 @@
+package changelog
+
+import (
+	"sort"
+	"strings"
+)
+
+// Section is a section of a version's changelog, e.g. "Added", with its
+// entries in the order they were written
+type Section struct {
+	Name    string
+	Entries []Entry
+}
+
+// SectionOrder is a canonical order of section names. Names are matched
+// case-insensitively, and sections it doesn't list go after the ones it does.
+type SectionOrder []string
+
+// DefaultSectionOrder is the order used by [Keep a Changelog](https://keepachangelog.com/)
+var DefaultSectionOrder = SectionOrder{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// ParseSectionOrder parses a comma-separated list of section names, e.g.
+// "Security,Fixed,Added"
+func ParseSectionOrder(sectionOrder string) SectionOrder {
+	var order SectionOrder
+	for _, name := range strings.Split(sectionOrder, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			order = append(order, name)
+		}
+	}
+
+	return order
+}
+
+// rank returns the position of a section in the order
+func (order SectionOrder) rank(name string) int {
+	for i, orderedName := range order {
+		if strings.EqualFold(orderedName, name) {
+			return i
+		}
+	}
+
+	return len(order)
+}
+
+// Less reports whether section `a` goes before section `b`
+func (order SectionOrder) Less(a string, b string) bool {
+	return order.rank(a) < order.rank(b)
+}
+
+// SortSections sorts a version's sections in place. Sections that rank the same
+// keep their source order.
+func (order SectionOrder) SortSections(sections []Section) {
+	sort.SliceStable(sections, func(i, j int) bool {
+		return order.Less(sections[i].Name, sections[j].Name)
+	})
+}
+
+// canonical returns the spelling the order uses for a section name, or the name
+// itself if the order doesn't list it
+func (order SectionOrder) canonical(name string) string {
+	if rank := order.rank(name); rank < len(order) {
+		return order[rank]
+	}
+
+	return name
+}
+
+// SectionAliases maps variants of section names, e.g. "Bug Fixes", to their
+// canonical names, e.g. "Fixed". Variants are matched case-insensitively.
+type SectionAliases map[string]string
+
+// DefaultSectionAliases maps the common variants of the Keep a Changelog
+// sections to them
+var DefaultSectionAliases = SectionAliases{
+	"added":        "Added",
+	"add":          "Added",
+	"additions":    "Added",
+	"feature":      "Added",
+	"features":     "Added",
+	"new":          "Added",
+	"new features": "Added",
+
+	"changed":      "Changed",
+	"change":       "Changed",
+	"changes":      "Changed",
+	"enhancements": "Changed",
+	"improvements": "Changed",
+	"updated":      "Changed",
+	"updates":      "Changed",
+
+	"deprecated":   "Deprecated",
+	"deprecations": "Deprecated",
+
+	"removed":  "Removed",
+	"remove":   "Removed",
+	"removals": "Removed",
+
+	"fixed":     "Fixed",
+	"fix":       "Fixed",
+	"fixes":     "Fixed",
+	"bug fix":   "Fixed",
+	"bug fixes": "Fixed",
+	"bugfix":    "Fixed",
+	"bugfixes":  "Fixed",
+
+	"security":       "Security",
+	"security fix":   "Security",
+	"security fixes": "Security",
+}
+
+// NewSectionAliases returns the default aliases extended with `overrides`,
+// which map variants to canonical names and take precedence over the defaults.
+// Overriding a default canonical name renames it, e.g. with
+// `{"fixed": "Bug Fixes"}` every default alias of "Fixed" maps to "Bug Fixes".
+func NewSectionAliases(overrides map[string]string) SectionAliases {
+	aliases := SectionAliases{}
+	for variant, canonical := range DefaultSectionAliases {
+		aliases[variant] = canonical
+	}
+
+	renamed := map[string]string{}
+	for variant, canonical := range overrides {
+		renamed[strings.ToLower(strings.TrimSpace(variant))] = canonical
+	}
+
+	for variant, canonical := range aliases {
+		if newName, ok := renamed[strings.ToLower(canonical)]; ok {
+			aliases[variant] = newName
+		}
+	}
+
+	// Canonical names are always their own alias, but variants that are
+	// overridden explicitly win
+	for _, canonical := range renamed {
+		aliases[strings.ToLower(strings.TrimSpace(canonical))] = canonical
+	}
+	for variant, canonical := range renamed {
+		aliases[variant] = canonical
+	}
+
+	return aliases
+}
+
+// Canonical returns the canonical name of a section, and whether the name is
+// known at all. Unknown names are returned as they are.
+func (aliases SectionAliases) Canonical(name string) (string, bool) {
+	canonical, ok := aliases[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return name, false
+	}
+
+	return canonical, true
+}
+
+// CanonicalNames returns the names that aliases map to: those of the default
+// order first, in that order, and then any others alphabetically
+func (aliases SectionAliases) CanonicalNames() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, name := range DefaultSectionOrder {
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	var others []string
+	for _, canonical := range aliases {
+		if !seen[canonical] {
+			seen[canonical] = true
+			others = append(others, canonical)
+		}
+	}
+	sort.Strings(others)
+
+	return append(names, others...)
+}
+
+// Normalize renames the sections of a version to their canonical names in
+// place, merging sections that turn out to be the same. Names it doesn't know
+// are title-cased, e.g. "known issues" becomes "Known Issues", so that they
+// merge regardless of how they're capitalized. It returns the names of the
+// sections it doesn't know as they were written.
+func (aliases SectionAliases) Normalize(c *VersionChangelog) []string {
+	var unknown []string
+	var sections []Section
+	for _, section := range c.Sections {
+		name, ok := aliases.Canonical(section.Name)
+		if !ok && section.Name != "" && section.Name != "_" {
+			unknown = append(unknown, section.Name)
+			name = strings.Title(strings.ToLower(strings.TrimSpace(section.Name)))
+		}
+
+		merged := false
+		for i := range sections {
+			if sections[i].Name == name {
+				sections[i].Entries = append(sections[i].Entries, section.Entries...)
+				merged = true
+				break
+			}
+		}
+
+		if !merged {
+			sections = append(sections, Section{Name: name, Entries: section.Entries})
+		}
+	}
+	c.Sections = sections
+
+	return unknown
+}
+
+// Entries returns the entries of a section, matching its name
+// case-insensitively, or nil if the version has no such section
+func (c *VersionChangelog) Entries(section string) []Entry {
+	for _, s := range c.Sections {
+		if strings.EqualFold(s.Name, section) {
+			return s.Entries
+		}
+	}
+
+	return nil
+}
+
+// addEntry appends an entry to a section, adding the section if it's new
+func (c *VersionChangelog) addEntry(section string, entry Entry) {
+	for i := range c.Sections {
+		if c.Sections[i].Name == section {
+			c.Sections[i].Entries = append(c.Sections[i].Entries, entry)
+			return
+		}
+	}
+
+	c.Sections = append(c.Sections, Section{Name: section, Entries: []Entry{entry}})
+}
@@ -0,0 +1,172 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sectionNames(sections []Section) []string {
+	var names []string
+	for _, section := range sections {
+		names = append(names, section.Name)
+	}
+
+	return names
+}
+
+func TestParseSectionOrder(t *testing.T) {
+	assert.Equal(t, SectionOrder{"Security", "Fixed", "Added"}, ParseSectionOrder(" Security, Fixed,,Added "))
+	assert.Empty(t, ParseSectionOrder(""))
+}
+
+func TestSectionOrderSortSections(t *testing.T) {
+	sections := []Section{
+		{Name: "Security"},
+		{Name: "Performance"},
+		{Name: "fixed"},
+		{Name: "Documentation"},
+		{Name: "Added"},
+	}
+
+	DefaultSectionOrder.SortSections(sections)
+	assert.Equal(t, []string{"Added", "fixed", "Security", "Performance", "Documentation"}, sectionNames(sections))
+
+	SectionOrder{"Documentation", "Security"}.SortSections(sections)
+	assert.Equal(t, []string{"Documentation", "Security", "Added", "fixed", "Performance"}, sectionNames(sections))
+}
+
+func TestParsePreservesSectionOrder(t *testing.T) {
+	changelogs, err := Parse("repo", `# Changelog
+## 1.0.0
+
+### Security
+- security 1
+
+### Fixed
+- fix 1
+
+### Added
+- add 1
+
+### Fixed
+- fix 2
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{"Security", "Fixed", "Added"}, sectionNames(changelogs[0].Sections))
+	assert.Equal(t, []string{"fix 1", "fix 2"}, []string{
+		changelogs[0].Entries("fixed")[0].Text,
+		changelogs[0].Entries("fixed")[1].Text,
+	})
+	assert.Nil(t, changelogs[0].Entries("Removed"))
+}
+
+func TestSectionAliases(t *testing.T) {
+	aliases := NewSectionAliases(map[string]string{
+		"Bug Fixes": "Bugs",
+		"Chores":    "Maintenance",
+	})
+
+	for name, expected := range map[string]string{
+		"Fixed":          "Fixed",
+		"FIXES":          "Fixed",
+		" bugfix ":       "Fixed",
+		"Bug Fixes":      "Bugs",
+		"bugs":           "Bugs",
+		"chores":         "Maintenance",
+		"New Features":   "Added",
+		"Security Fixes": "Security",
+	} {
+		canonical, ok := aliases.Canonical(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, canonical, name)
+	}
+
+	canonical, ok := aliases.Canonical("Known Issues")
+	assert.False(t, ok)
+	assert.Equal(t, "Known Issues", canonical)
+
+	// The defaults are left as they are
+	canonical, _ = DefaultSectionAliases.Canonical("Bug Fixes")
+	assert.Equal(t, "Fixed", canonical)
+}
+
+func TestNewSectionAliasesRenamingDefault(t *testing.T) {
+	aliases := NewSectionAliases(map[string]string{"fixed": "Bug Fixes"})
+
+	for _, name := range []string{"Fixed", "Bug Fixes", "bugfix", "Fixes"} {
+		canonical, ok := aliases.Canonical(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, "Bug Fixes", canonical, name)
+	}
+
+	versionChangelog := &VersionChangelog{
+		Sections: []Section{
+			{Name: "Fixed", Entries: entries("fix 1")},
+			{Name: "Bug Fixes", Entries: entries("fix 2")},
+		},
+	}
+	aliases.Normalize(versionChangelog)
+
+	assert.Equal(t, []Section{
+		{Name: "Bug Fixes", Entries: entries("fix 1", "fix 2")},
+	}, versionChangelog.Sections)
+}
+
+func TestSectionAliasesNormalize(t *testing.T) {
+	versionChangelog := &VersionChangelog{
+		Sections: []Section{
+			{Name: "Fixes", Entries: entries("fix 1")},
+			{Name: "Known Issues", Entries: entries("issue 1")},
+			{Name: "Added", Entries: entries("add 1")},
+			{Name: "Bug Fixes", Entries: entries("fix 2")},
+		},
+	}
+
+	unknown := DefaultSectionAliases.Normalize(versionChangelog)
+
+	assert.Equal(t, []string{"Known Issues"}, unknown)
+	assert.Equal(t, []Section{
+		{Name: "Fixed", Entries: entries("fix 1", "fix 2")},
+		{Name: "Known Issues", Entries: entries("issue 1")},
+		{Name: "Added", Entries: entries("add 1")},
+	}, versionChangelog.Sections)
+}
+
+func TestSectionAliasesNormalizeUnknownCasing(t *testing.T) {
+	aliases := NewSectionAliases(map[string]string{"Chores": "CI/CD"})
+
+	first := &VersionChangelog{
+		Repo: "a-repo",
+		Sections: []Section{
+			{Name: "fixed stuff", Entries: entries("stuff 1")},
+			{Name: "chores", Entries: entries("chore 1")},
+		},
+	}
+	second := &VersionChangelog{
+		Repo: "b-repo",
+		Sections: []Section{
+			{Name: "Fixed Stuff", Entries: entries("stuff 2")},
+			{Name: "FIXED STUFF", Entries: entries("stuff 3")},
+		},
+	}
+
+	assert.Equal(t, []string{"fixed stuff"}, aliases.Normalize(first))
+	assert.Equal(t, []string{"Fixed Stuff", "FIXED STUFF"}, aliases.Normalize(second))
+
+	// Configured canonical names keep their spelling
+	assert.Equal(t, []string{"Fixed Stuff", "CI/CD"}, sectionNames(first.Sections))
+	assert.Equal(t, []Section{
+		{Name: "Fixed Stuff", Entries: entries("stuff 2", "stuff 3")},
+	}, second.Sections)
+
+	unified := NewUnifiedChangelog(DefaultSectionOrder, second, first)
+	if assert.Len(t, unified, 2) {
+		assert.Equal(t, "Fixed Stuff", unified[0].Name)
+		assert.Len(t, unified[0].Entries, 3)
+		assert.Equal(t, "CI/CD", unified[1].Name)
+	}
+}
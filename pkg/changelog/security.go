@@ -0,0 +1,111 @@
+package changelog
+
+import (
+	"sort"
+	"strings"
+)
+
+// SecuritySection is the Keep a Changelog section of security fixes
+const SecuritySection = "Security"
+
+// Advisory is a security fix listed in the changelog of a component
+type Advisory struct {
+	// ID is the CVE or GHSA ID of the vulnerability, or empty for entries of
+	// the Security section that don't name one
+	ID   string `json:"id,omitempty"`
+	Repo string `json:"component"`
+	// Version is the version of the component that has the fix
+	Version string `json:"fixed_in"`
+	// URL links to the advisory, or to whatever the entry links to if it has
+	// no ID
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description"`
+}
+
+// advisoryURL returns the link of an advisory ID: one of the URLs of the
+// entry that mentions it, or else its entry in the NVD or GitHub advisory
+// database
+func advisoryURL(urls []string, id string) string {
+	for _, url := range urls {
+		if strings.Contains(strings.ToLower(url), strings.ToLower(id)) {
+			return url
+		}
+	}
+
+	if strings.HasPrefix(id, "GHSA-") {
+		return "https://github.com/advisories/" + id
+	}
+
+	return "https://nvd.nist.gov/vuln/detail/" + id
+}
+
+// advisoryMetadata collects the advisory IDs and URLs of an entry and its
+// nested entries, e.g. of a dependency bump that lists the CVEs it fixes
+func advisoryMetadata(entry Entry) ([]string, []string) {
+	ids := append(append([]string{}, entry.CVEs...), entry.GHSAs...)
+	urls := append([]string{}, entry.URLs...)
+	for _, child := range entry.Children {
+		childIDs, childURLs := advisoryMetadata(child)
+		ids = append(ids, childIDs...)
+		urls = append(urls, childURLs...)
+	}
+
+	return ids, urls
+}
+
+// Advisories extracts the security fixes of all components: the entries of the
+// Security section and any other entries that name a CVE or GHSA ID, including
+// in their nested entries. Entries that name several IDs are listed once per
+// ID. Advisories are sorted by component and then ID, with those without an ID
+// last, and otherwise keep the changelog order.
+func (c UnifiedChangelog) Advisories() []Advisory {
+	var advisories []Advisory
+	seen := map[Advisory]bool{}
+	add := func(advisory Advisory) {
+		if !seen[advisory] {
+			seen[advisory] = true
+			advisories = append(advisories, advisory)
+		}
+	}
+
+	for _, section := range c {
+		for _, entry := range section.Entries {
+			ids, urls := advisoryMetadata(entry.Entry)
+			for _, id := range ids {
+				add(Advisory{
+					ID:          id,
+					Repo:        entry.Repo,
+					Version:     entry.Version,
+					URL:         advisoryURL(urls, id),
+					Description: entry.Text,
+				})
+			}
+
+			if len(ids) == 0 && strings.EqualFold(section.Name, SecuritySection) {
+				advisory := Advisory{
+					Repo:        entry.Repo,
+					Version:     entry.Version,
+					Description: entry.Text,
+				}
+				if len(urls) > 0 {
+					advisory.URL = urls[0]
+				}
+				add(advisory)
+			}
+		}
+	}
+
+	sort.SliceStable(advisories, func(i, j int) bool {
+		a, b := advisories[i], advisories[j]
+		switch {
+		case a.Repo != b.Repo:
+			return a.Repo < b.Repo
+		case (a.ID == "") != (b.ID == ""):
+			return a.ID != ""
+		default:
+			return a.ID < b.ID
+		}
+	})
+
+	return advisories
+}
@@ -0,0 +1,85 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedChangelog_Advisories(t *testing.T) {
+	rack := NewEntry("cyberark/conjur", "Bumped `rack` to 2.2.3")
+	rack.Children = []Entry{NewEntry("cyberark/conjur", "Fixes CVE-2020-8184 (https://github.com/rack/rack/security/advisories/GHSA-j6w9-fv6q-3q52)")}
+
+	unified := NewUnifiedChangelog(
+		DefaultSectionOrder,
+		&VersionChangelog{
+			Repo:    "cyberark/secretless-broker",
+			Version: "1.7.0",
+			Sections: []Section{
+				{Name: "Fixed", Entries: []Entry{
+					NewEntry("", "Fixed a crash"),
+					NewEntry("", "Patched GHSA-j6w9-fv6q-3q52 and CVE-2020-0002"),
+				}},
+				{Name: "Security", Entries: []Entry{
+					NewEntry("", "Hardened the TLS defaults, see https://example.com/tls"),
+					NewEntry("", "Upgraded Go for CVE-2020-0001"),
+				}},
+			},
+		},
+		&VersionChangelog{
+			Repo:     "cyberark/conjur",
+			Version:  "1.11.0",
+			Sections: []Section{{Name: "Changed", Entries: []Entry{rack}}},
+		},
+	)
+
+	assert.Equal(t, []Advisory{
+		{
+			ID:          "CVE-2020-8184",
+			Repo:        "cyberark/conjur",
+			Version:     "1.11.0",
+			URL:         "https://nvd.nist.gov/vuln/detail/CVE-2020-8184",
+			Description: "Bumped `rack` to 2.2.3",
+		},
+		{
+			ID:          "GHSA-j6w9-fv6q-3q52",
+			Repo:        "cyberark/conjur",
+			Version:     "1.11.0",
+			URL:         "https://github.com/rack/rack/security/advisories/GHSA-j6w9-fv6q-3q52",
+			Description: "Bumped `rack` to 2.2.3",
+		},
+		{
+			ID:          "CVE-2020-0001",
+			Repo:        "cyberark/secretless-broker",
+			Version:     "1.7.0",
+			URL:         "https://nvd.nist.gov/vuln/detail/CVE-2020-0001",
+			Description: "Upgraded Go for CVE-2020-0001",
+		},
+		{
+			ID:          "CVE-2020-0002",
+			Repo:        "cyberark/secretless-broker",
+			Version:     "1.7.0",
+			URL:         "https://nvd.nist.gov/vuln/detail/CVE-2020-0002",
+			Description: "Patched GHSA-j6w9-fv6q-3q52 and CVE-2020-0002",
+		},
+		{
+			ID:          "GHSA-j6w9-fv6q-3q52",
+			Repo:        "cyberark/secretless-broker",
+			Version:     "1.7.0",
+			URL:         "https://github.com/advisories/GHSA-j6w9-fv6q-3q52",
+			Description: "Patched GHSA-j6w9-fv6q-3q52 and CVE-2020-0002",
+		},
+		{
+			Repo:        "cyberark/secretless-broker",
+			Version:     "1.7.0",
+			URL:         "https://example.com/tls",
+			Description: "Hardened the TLS defaults, see https://example.com/tls",
+		},
+	}, unified.Advisories())
+}
+
+func TestAdvisoryURL(t *testing.T) {
+	urls := []string{"https://example.com", "https://github.com/rack/rack/security/advisories/GHSA-j6w9-fv6q-3q52"}
+	assert.Equal(t, urls[1], advisoryURL(urls, "GHSA-j6w9-fv6q-3q52"))
+	assert.Equal(t, "https://nvd.nist.gov/vuln/detail/CVE-2020-8184", advisoryURL(urls, "CVE-2020-8184"))
+}
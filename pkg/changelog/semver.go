@@ -0,0 +1,166 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/version"
+)
+
+// Semver rules, as reported in SemverViolation.Rule
+const (
+	RuleFeatureInPatch       = "feature-in-patch"
+	RuleBreakingWithoutMajor = "breaking-without-major"
+	RuleMajorWithoutBreaking = "major-without-breaking"
+	RuleMissingVersion       = "missing-version"
+)
+
+// SemverViolation is a release whose version bump doesn't match its changes
+type SemverViolation struct {
+	Repo    string `json:"repo"`
+	Version string `json:"version"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (violation SemverViolation) String() string {
+	return fmt.Sprintf("%s@%s: %s (%s)", violation.Repo, violation.Version, violation.Message, violation.Rule)
+}
+
+// CheckSemver checks that each of `versions` is bumped from the version before
+// it in line with its changelog, as [semantic versioning](https://semver.org/)
+// asks:
+//
+// - patch releases have no "Added" or "Deprecated" entries
+//
+// - only major releases have breaking changes or "Removed" entries
+//
+// - major releases have breaking changes or "Removed" entries
+//
+// - every one of `releases`, e.g. the GitHub releases, has a changelog
+//
+// The version before is the highest lower one of the changelog or `releases`.
+// Major version 0 is for initial development so breaking changes aren't
+// checked before 1.0.0. Versions that aren't semantic versions are skipped.
+// Sections are matched through `aliases`, e.g. those configured for a suite, or
+// DefaultSectionAliases if nil.
+func CheckSemver(
+	repo string,
+	index Index,
+	releases []string,
+	versions []string,
+	aliases SectionAliases,
+) []SemverViolation {
+	if aliases == nil {
+		aliases = DefaultSectionAliases
+	}
+
+	var violations []SemverViolation
+	report := func(versionStr string, rule string, format string, args ...interface{}) {
+		violations = append(violations, SemverViolation{
+			Repo:    repo,
+			Version: normalizeVersion(versionStr),
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	released := map[string]bool{}
+	for _, release := range releases {
+		released[normalizeVersion(release)] = true
+	}
+
+	var knownVersions []string
+	for _, versionStr := range append(index.Versions(), releases...) {
+		if _, err := semver.NewVersion(normalizeVersion(versionStr)); err == nil {
+			knownVersions = append(knownVersions, versionStr)
+		}
+	}
+
+	for _, versionStr := range versions {
+		current, err := semver.NewVersion(normalizeVersion(versionStr))
+		if err != nil {
+			continue
+		}
+
+		versionChangelog := index.Get(versionStr)
+		if versionChangelog == nil {
+			if released[normalizeVersion(versionStr)] {
+				report(versionStr, RuleMissingVersion, "release %s is not in the CHANGELOG", versionStr)
+			}
+			continue
+		}
+
+		previousStr, err := version.PreviousVersion(knownVersions, versionStr)
+		if err != nil || previousStr == "" {
+			continue
+		}
+		previous, err := semver.NewVersion(normalizeVersion(previousStr))
+		if err != nil {
+			continue
+		}
+
+		breaking, additions := semverChanges(versionChangelog, aliases)
+		switch {
+		case current.Major > previous.Major:
+			// 1.0.0 marks the API as stable rather than changing it
+			if previous.Major > 0 && breaking == "" {
+				report(versionStr, RuleMajorWithoutBreaking, "major release after %s has no breaking changes or removals", normalizeVersion(previousStr))
+			}
+		case current.Major > 0 && breaking != "":
+			report(versionStr, RuleBreakingWithoutMajor, "has %s but isn't a major release after %s", breaking, normalizeVersion(previousStr))
+		}
+
+		if current.Major == previous.Major && current.Minor == previous.Minor && additions != "" {
+			report(versionStr, RuleFeatureInPatch, "patch release after %s has %s, which need a minor release", normalizeVersion(previousStr), additions)
+		}
+	}
+
+	return violations
+}
+
+// semverChanges describes the changes of a version that semver cares about,
+// e.g. `1 breaking change(s) and "Removed" entries`: the breaking ones, i.e.
+// entries marked as breaking and the "Removed" section, and the additions, i.e.
+// the "Added" and "Deprecated" sections. Either is empty if there are none.
+func semverChanges(versionChangelog *VersionChangelog, aliases SectionAliases) (string, string) {
+	breakingEntries := 0
+	removed := false
+	var additions []string
+	for _, section := range versionChangelog.Sections {
+		if len(section.Entries) == 0 {
+			continue
+		}
+
+		canonical, _ := aliases.Canonical(section.Name)
+		switch canonical {
+		case "Removed":
+			removed = true
+		case "Added", "Deprecated":
+			additions = append(additions, fmt.Sprintf("%q", canonical))
+		}
+
+		for _, entry := range section.Entries {
+			if entry.Breaking {
+				breakingEntries++
+			}
+		}
+	}
+
+	var breaking []string
+	if breakingEntries > 0 {
+		breaking = append(breaking, fmt.Sprintf("%d breaking change(s)", breakingEntries))
+	}
+	if removed {
+		breaking = append(breaking, `"Removed" entries`)
+	}
+
+	additionsDescription := ""
+	if len(additions) > 0 {
+		additionsDescription = strings.Join(additions, " and ") + " entries"
+	}
+
+	return strings.Join(breaking, " and "), additionsDescription
+}
@@ -0,0 +1,111 @@
+package changelog
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSemver(t *testing.T) {
+	contents, err := ioutil.ReadFile("testdata/changelog.semver.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	index, err := NewIndex("test-repo", string(contents))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("changelog versions", func(t *testing.T) {
+		violations := CheckSemver("test-repo", index, nil, index.Versions(), nil)
+		assert.Equal(t, []SemverViolation{
+			{
+				Repo:    "test-repo",
+				Version: "1.1.1",
+				Rule:    RuleFeatureInPatch,
+				Message: `patch release after 1.1.0 has "Added" and "Deprecated" entries, which need a minor release`,
+			},
+			{
+				Repo:    "test-repo",
+				Version: "1.2.0",
+				Rule:    RuleBreakingWithoutMajor,
+				Message: `has "Removed" entries but isn't a major release after 1.1.1`,
+			},
+			{
+				Repo:    "test-repo",
+				Version: "3.0.0",
+				Rule:    RuleMajorWithoutBreaking,
+				Message: "major release after 2.0.0 has no breaking changes or removals",
+			},
+		}, violations)
+	})
+
+	t.Run("releases", func(t *testing.T) {
+		releases := []string{"v2.0.0", "v2.0.1", "v3.0.0"}
+		violations := CheckSemver("test-repo", index, releases, []string{"v2.0.1", "v3.0.0"}, nil)
+		assert.Equal(t, []SemverViolation{
+			{
+				Repo:    "test-repo",
+				Version: "2.0.1",
+				Rule:    RuleMissingVersion,
+				Message: "release v2.0.1 is not in the CHANGELOG",
+			},
+			{
+				Repo:    "test-repo",
+				Version: "3.0.0",
+				Rule:    RuleMajorWithoutBreaking,
+				Message: "major release after 2.0.1 has no breaking changes or removals",
+			},
+		}, violations)
+	})
+
+	t.Run("breaking patch release", func(t *testing.T) {
+		index, err := NewIndex("test-repo", `# Changelog
+
+## 1.0.1 - 2020-01-02
+
+### Fixed
+- BREAKING: Fixed the exit code
+
+## 1.0.0 - 2020-01-01
+
+### Added
+- First release
+`)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		violations := CheckSemver("test-repo", index, nil, []string{"1.0.1"}, nil)
+		if assert.Len(t, violations, 1) {
+			assert.Equal(t, "test-repo@1.0.1: has 1 breaking change(s) but isn't a major release after 1.0.0 (breaking-without-major)", violations[0].String())
+		}
+	})
+	t.Run("configured section aliases", func(t *testing.T) {
+		index, err := NewIndex("test-repo", `# Changelog
+
+## 1.1.0 - 2020-01-02
+
+### Dropped
+- The old flag
+
+## 1.0.0 - 2020-01-01
+
+### Added
+- First release
+`)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Empty(t, CheckSemver("test-repo", index, nil, []string{"1.1.0"}, nil))
+
+		aliases := NewSectionAliases(map[string]string{"Dropped": "Removed"})
+		violations := CheckSemver("test-repo", index, nil, []string{"1.1.0"}, aliases)
+		if assert.Len(t, violations, 1) {
+			assert.Equal(t, RuleBreakingWithoutMajor, violations[0].Rule)
+		}
+	})
+}
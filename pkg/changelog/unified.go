@@ -4,73 +4,201 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 )
 
-// UnifiedChangelog is a map of repos to their changelog sections
-type UnifiedChangelog map[string][]string
+// UnifiedEntry is a changelog entry of a specific version of a repo
+type UnifiedEntry struct {
+	Entry
+	Version string
+}
+
+func (entry UnifiedEntry) String() string {
+	return fmt.Sprintf("`%s@%s`: %s", entry.Repo, entry.Version, entry.Text)
+}
+
+// UnifiedSection is a section of the unified changelog
+type UnifiedSection struct {
+	Name    string
+	Entries []UnifiedEntry
+}
+
+// UnifiedChangelog is the ordered sections of the changelogs of all repos
+type UnifiedChangelog []UnifiedSection
+
+// section returns the section with a specific name, matched
+// case-insensitively, or nil if there is none
+func (c UnifiedChangelog) section(name string) *UnifiedSection {
+	for i := range c {
+		if strings.EqualFold(c[i].Name, name) {
+			return &c[i]
+		}
+	}
+
+	return nil
+}
 
 func (c UnifiedChangelog) String() string {
 	res := ""
 
-	var sections []string
-	for section := range c {
-		sections = append(sections, section)
+	for _, section := range c {
+		if section.Name == "_" {
+			continue
+		}
+
+		res = res + fmt.Sprintf("### %s\n", section.Name)
+		for _, entry := range section.Entries {
+			res = res + fmt.Sprintf("- %s%s\n", entry, entry.ChildrenMarkdown("  "))
+		}
+
+		res = res + "\n"
 	}
-	sort.Strings(sections)
 
-	for _, section := range sections {
-		if section == "_" {
-			continue
+	return res
+}
+
+// VersionChangelog turns the unified changelog into the changelog of a single
+// version, e.g. that of the suite release, so that it can be written out with
+// Markdown. Entries are prefixed with the repo and version they come from.
+func (c UnifiedChangelog) VersionChangelog(version string, date time.Time) *VersionChangelog {
+	res := &VersionChangelog{
+		Version:    version,
+		Date:       date.Format(DefaultDateFormat),
+		ParsedDate: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+	}
+
+	for _, section := range c {
+		var entries []Entry
+		for _, unifiedEntry := range section.Entries {
+			entry := unifiedEntry.Entry
+			prefix := fmt.Sprintf("`%s@%s`: ", unifiedEntry.Repo, unifiedEntry.Version)
+			entry.Text = prefix + entry.Text
+			if entry.Markdown != "" {
+				entry.Markdown = prefix + entry.Markdown
+			}
+
+			entries = append(entries, entry)
+		}
+
+		res.Sections = append(res.Sections, Section{Name: section.Name, Entries: entries})
+	}
+
+	return res
+}
+
+// Filter returns a unified changelog of just the entries that `keep` accepts.
+// Sections without any such entries are left out.
+func (c UnifiedChangelog) Filter(keep func(UnifiedEntry) bool) UnifiedChangelog {
+	res := UnifiedChangelog{}
+	for _, section := range c {
+		var entries []UnifiedEntry
+		for _, entry := range section.Entries {
+			if keep(entry) {
+				entries = append(entries, entry)
+			}
 		}
 
-		sectionValues := c[section]
+		if len(entries) > 0 {
+			res = append(res, UnifiedSection{Name: section.Name, Entries: entries})
+		}
+	}
 
-		res = res + fmt.Sprintf("### %s\n", section)
-		for _, sectionValue := range sectionValues {
-			res = res + fmt.Sprintf("- %s\n", sectionValue)
+	return res
+}
+
+// Breaking returns the breaking changes of all sections, in section order
+func (c UnifiedChangelog) Breaking() []UnifiedEntry {
+	var breaking []UnifiedEntry
+	for _, section := range c {
+		for _, entry := range section.Entries {
+			if entry.Breaking {
+				breaking = append(breaking, entry)
+			}
 		}
+	}
 
-		res = res + "\n"
+	return breaking
+}
+
+// CVEs returns the distinct CVE IDs mentioned by any entry, sorted
+func (c UnifiedChangelog) CVEs() []string {
+	seen := map[string]bool{}
+	var cves []string
+	for _, section := range c {
+		for _, entry := range section.Entries {
+			for _, cve := range entry.CVEs {
+				if !seen[cve] {
+					seen[cve] = true
+					cves = append(cves, cve)
+				}
+			}
+		}
+	}
+	sort.Strings(cves)
+
+	return cves
+}
+
+// ByReference groups the entries by the issues and pull requests they
+// reference, e.g. to find every change related to `cyberark/conjur#45`
+func (c UnifiedChangelog) ByReference() map[Reference][]UnifiedEntry {
+	res := map[Reference][]UnifiedEntry{}
+	for _, section := range c {
+		for _, entry := range section.Entries {
+			for _, reference := range entry.References {
+				res[reference] = append(res[reference], entry)
+			}
+		}
 	}
 
 	return res
 }
 
 // NewUnifiedChangelog creates a unified changelog from various per-version and
-// per-repo changelogs
-func NewUnifiedChangelog(changelogs ...*VersionChangelog) UnifiedChangelog {
+// per-repo changelogs. Its sections are sorted in the specified order, with any
+// others following in the order they are first seen in. Section names are
+// expected to be normalized already, see SectionAliases.Normalize, which also
+// title-cases unknown names so that they're spelled the same across repos, and
+// entries without a section to be classified, see Classify.
+func NewUnifiedChangelog(order SectionOrder, changelogs ...*VersionChangelog) UnifiedChangelog {
 	res := UnifiedChangelog{}
 
-	sort.Slice(changelogs, func(i, j int) bool {
+	sort.SliceStable(changelogs, func(i, j int) bool {
 		return changelogs[i].Repo < changelogs[j].Repo
 	})
 
 	for _, changelog := range changelogs {
-		for section, sectionValues := range changelog.Sections {
-			// normalise section keys
-			section = strings.Title(strings.ToLower(section))
+		for _, section := range changelog.Sections {
+			// Sections are merged case-insensitively and spelled the way the
+			// order spells them
+			name := order.canonical(section.Name)
 
-			if section == "_" {
+			if name == "_" {
 				continue
 			}
 
-			if _, ok := res[section]; !ok {
-				res[section] = []string{}
+			unifiedSection := res.section(name)
+			if unifiedSection == nil {
+				res = append(res, UnifiedSection{Name: name})
+				unifiedSection = &res[len(res)-1]
 			}
 
-			for _, value := range sectionValues {
-				res[section] = append(
-					res[section],
-					fmt.Sprintf(
-						"`%s@%s`: %s",
-						changelog.Repo,
-						changelog.Version,
-						value,
-					),
+			for _, entry := range section.Entries {
+				entry.Repo = changelog.Repo
+				unifiedSection.Entries = append(
+					unifiedSection.Entries,
+					UnifiedEntry{
+						Entry:   entry,
+						Version: changelog.Version,
+					},
 				)
 			}
 		}
 	}
 
+	sort.SliceStable(res, func(i, j int) bool {
+		return order.Less(res[i].Name, res[j].Name)
+	})
+
 	return res
 }
@@ -2,58 +2,65 @@ package changelog
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// entries creates plain entries with no metadata
+func entries(texts ...string) []Entry {
+	res := []Entry{}
+	for _, text := range texts {
+		res = append(res, Entry{Text: text})
+	}
+
+	return res
+}
+
+func unifiedEntry(repo string, version string, text string) UnifiedEntry {
+	return UnifiedEntry{
+		Entry:   Entry{Repo: repo, Text: text},
+		Version: version,
+	}
+}
+
 func TestNewUnifiedChangelog(t *testing.T) {
 	expected := UnifiedChangelog{
-		"Added": {
-			"`x-repo@x-version`: add 1",
-			"`y-repo@y-version`: add 2",
+		{
+			Name: "Added",
+			Entries: []UnifiedEntry{
+				unifiedEntry("x-repo", "x-version", "add 1"),
+				unifiedEntry("y-repo", "y-version", "add 2"),
+			},
 		},
-		"Changed": {
-			"`x-repo@x-version`: change 1",
-			"`x-repo@x-version`: change 2",
-			"`y-repo@y-version`: change 3",
-			"`y-repo@y-version`: change 4",
+		{
+			Name: "Changed",
+			Entries: []UnifiedEntry{
+				unifiedEntry("x-repo", "x-version", "change 1"),
+				unifiedEntry("x-repo", "x-version", "change 2"),
+				unifiedEntry("y-repo", "y-version", "change 3"),
+				unifiedEntry("y-repo", "y-version", "change 4"),
+			},
 		},
 	}
 	actual := NewUnifiedChangelog(
+		DefaultSectionOrder,
 		&VersionChangelog{
 			Repo:    "x-repo",
 			Version: "x-version",
-			Sections: map[string][]string{
-				"ADded": {
-					"add 1",
-				},
-				"Changed": {
-					"change 1",
-					"change 2",
-				},
-				"_": {
-					"add 1",
-					"change 1",
-					"change 2",
-				},
+			Sections: []Section{
+				{Name: "ADded", Entries: entries("add 1")},
+				{Name: "Changed", Entries: entries("change 1", "change 2")},
+				{Name: "_", Entries: entries("add 1", "change 1", "change 2")},
 			},
 		},
 		&VersionChangelog{
 			Repo:    "y-repo",
 			Version: "y-version",
-			Sections: map[string][]string{
-				"Added": {
-					"add 2",
-				},
-				"changed": {
-					"change 3",
-					"change 4",
-				},
-				"_": {
-					"add 2",
-					"change 3",
-					"change 4",
-				},
+			Sections: []Section{
+				{Name: "Added", Entries: entries("add 2")},
+				{Name: "changed", Entries: entries("change 3", "change 4")},
+				{Name: "_", Entries: entries("add 2", "change 3", "change 4")},
 			},
 		},
 	)
@@ -74,17 +81,140 @@ func TestUnifiedChangelog_String(t *testing.T) {
 
 `
 	actual := UnifiedChangelog{
-		"Added": {
-			"`x-repo@x-version`: add 1",
-			"`y-repo@y-version`: add 2",
+		{
+			Name: "Added",
+			Entries: []UnifiedEntry{
+				unifiedEntry("x-repo", "x-version", "add 1"),
+				unifiedEntry("y-repo", "y-version", "add 2"),
+			},
 		},
-		"Changed": {
-			"`x-repo@x-version`: change 1",
-			"`x-repo@x-version`: change 2",
-			"`y-repo@y-version`: change 3",
-			"`y-repo@y-version`: change 4",
+		{
+			Name: "Changed",
+			Entries: []UnifiedEntry{
+				unifiedEntry("x-repo", "x-version", "change 1"),
+				unifiedEntry("x-repo", "x-version", "change 2"),
+				unifiedEntry("y-repo", "y-version", "change 3"),
+				unifiedEntry("y-repo", "y-version", "change 4"),
+			},
 		},
 	}.String()
 
 	assert.EqualValues(t, expected, actual)
 }
+
+func TestUnifiedChangelog_VersionChangelog(t *testing.T) {
+	date := time.Date(2020, 2, 19, 11, 58, 5, 0, time.UTC)
+	actual := UnifiedChangelog{
+		{
+			Name: "Added",
+			Entries: []UnifiedEntry{
+				unifiedEntry("x-repo", "x-version", "add 1"),
+			},
+		},
+		{
+			Name: "Fixed",
+			Entries: []UnifiedEntry{
+				{
+					Entry:   Entry{Repo: "y-repo", Text: "fix 1", Markdown: "fix [1](https://fix)"},
+					Version: "y-version",
+				},
+			},
+		},
+	}.VersionChangelog("11.22.33", date)
+
+	expected := `## [11.22.33] - 2020-02-19
+
+### Added
+- ` + "`x-repo@x-version`" + `: add 1
+
+### Fixed
+- ` + "`y-repo@y-version`" + `: fix [1](https://fix)
+`
+	assert.Equal(t, expected, Markdown([]*VersionChangelog{actual}, DefaultSectionOrder))
+	assert.Equal(t, "`x-repo@x-version`: add 1", actual.Sections[0].Entries[0].Text)
+}
+
+func TestUnifiedChangelogMetadata(t *testing.T) {
+	unified := NewUnifiedChangelog(
+		DefaultSectionOrder,
+		&VersionChangelog{
+			Repo:    "cyberark/conjur",
+			Version: "1.5.0",
+			Sections: []Section{
+				{
+					Name: "Changed",
+					Entries: []Entry{
+						NewEntry("cyberark/conjur", "Breaking change: new API (#10)"),
+						NewEntry("cyberark/conjur", "Faster startup"),
+					},
+				},
+				{
+					Name: "Security",
+					Entries: []Entry{
+						NewEntry("cyberark/conjur", "Fixed CVE-2020-0002 (cyberark/secretless-broker#7)"),
+					},
+				},
+			},
+		},
+		&VersionChangelog{
+			Repo:    "cyberark/secretless-broker",
+			Version: "1.4.2",
+			Sections: []Section{
+				{
+					Name: "Fixed",
+					Entries: []Entry{
+						NewEntry("cyberark/secretless-broker", "Fixed CVE-2020-0001 and CVE-2020-0002 (#7)"),
+					},
+				},
+			},
+		},
+	)
+
+	breaking := unified.Breaking()
+	if assert.Len(t, breaking, 1) {
+		assert.Equal(t, "`cyberark/conjur@1.5.0`: Breaking change: new API (#10)", breaking[0].String())
+	}
+
+	assert.Equal(t, []string{"CVE-2020-0001", "CVE-2020-0002"}, unified.CVEs())
+
+	byReference := unified.ByReference()
+	assert.Len(t, byReference, 2)
+	assert.Len(t, byReference[Reference{Repo: "cyberark/conjur", Number: 10}], 1)
+	assert.Len(t, byReference[Reference{Repo: "cyberark/secretless-broker", Number: 7}], 2)
+
+	security := unified.Filter(func(entry UnifiedEntry) bool {
+		return len(entry.CVEs) > 0
+	})
+	if assert.Len(t, security, 2) {
+		assert.Equal(t, "Fixed", security[0].Name)
+		assert.Equal(t, "Security", security[1].Name)
+	}
+}
+
+func TestNewUnifiedChangelogSectionOrder(t *testing.T) {
+	unified := NewUnifiedChangelog(
+		DefaultSectionOrder,
+		&VersionChangelog{
+			Repo: "x-repo",
+			Sections: []Section{
+				{Name: "Security", Entries: entries("security 1")},
+				{Name: "Performance", Entries: entries("performance 1")},
+				{Name: "Fixed", Entries: entries("fix 1")},
+			},
+		},
+		&VersionChangelog{
+			Repo: "y-repo",
+			Sections: []Section{
+				{Name: "Documentation", Entries: entries("docs 1")},
+				{Name: "Removed", Entries: entries("removal 1")},
+				{Name: "Added", Entries: entries("add 1")},
+			},
+		},
+	)
+
+	var names []string
+	for _, section := range unified {
+		names = append(names, section.Name)
+	}
+	assert.Equal(t, []string{"Added", "Removed", "Fixed", "Security", "Performance", "Documentation"}, names)
+}
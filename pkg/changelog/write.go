@@ -0,0 +1,349 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gomarkdown/markdown/ast"
+	markdownparser "github.com/gomarkdown/markdown/parser"
+)
+
+// Markdown renders versions in the [keep a changelog](https://keepachangelog.com/)
+// format: a `## [version] - date` heading for each version, followed by its
+// sections in the specified order and, at the very end, the link reference
+// definitions of the versions that have a URL. Parsed dates are written as
+// YYYY-MM-DD and others as they are.
+func Markdown(changelogs []*VersionChangelog, order SectionOrder) string {
+	var markdown strings.Builder
+	var definitions []string
+
+	for i, versionChangelog := range changelogs {
+		if i > 0 {
+			markdown.WriteString("\n")
+		}
+		markdown.WriteString(versionHeading(versionChangelog) + "\n")
+
+		for _, section := range orderedSections(versionChangelog.Sections, order) {
+			markdown.WriteString("\n")
+			if section.Name != "" {
+				markdown.WriteString("### " + section.Name + "\n")
+			}
+
+			for _, entry := range section.Entries {
+				writeEntry(&markdown, entry, "")
+			}
+		}
+
+		if versionChangelog.URL != "" {
+			definitions = append(definitions, fmt.Sprintf("[%s]: %s", versionChangelog.Version, versionChangelog.URL))
+		}
+	}
+
+	if len(definitions) > 0 {
+		markdown.WriteString("\n" + strings.Join(definitions, "\n") + "\n")
+	}
+
+	return markdown.String()
+}
+
+// versionHeading returns the `## [version] - date` heading of a version
+func versionHeading(versionChangelog *VersionChangelog) string {
+	heading := fmt.Sprintf("## [%s]", versionChangelog.Version)
+	if versionChangelog.Unreleased {
+		return heading
+	}
+
+	date := versionChangelog.FormatDate(DefaultDateFormat)
+	if date == "" {
+		return heading
+	}
+
+	return heading + " - " + date
+}
+
+// orderedSections returns a sorted copy of the sections. Entries without a
+// section are listed first since they have no heading to separate them from
+// the section before.
+func orderedSections(sections []Section, order SectionOrder) []Section {
+	var ordered []Section
+	var named []Section
+	for _, section := range sections {
+		if section.Name == "" {
+			ordered = append(ordered, section)
+		} else {
+			named = append(named, section)
+		}
+	}
+	order.SortSections(named)
+
+	return append(ordered, named...)
+}
+
+// writeEntry writes an entry as a list item, with any continuation lines and
+// nested entries indented under it
+func writeEntry(markdown *strings.Builder, entry Entry, indent string) {
+	text := entry.Markdown
+	if text == "" {
+		text = entry.Text
+	}
+
+	lines := strings.Split(text, "\n")
+	markdown.WriteString(indent + "- " + lines[0] + "\n")
+	// Blank lines separate the paragraphs of loose list items, which the
+	// parser only keeps in the item if they're indented by four spaces
+	continuation := indent + "  "
+	for _, line := range lines[1:] {
+		if line == "" {
+			markdown.WriteString("\n")
+			continuation = indent + "    "
+			continue
+		}
+		markdown.WriteString(continuation + line + "\n")
+	}
+
+	for _, child := range entry.Children {
+		writeEntry(markdown, child, indent+"  ")
+	}
+}
+
+// Format reformats a changelog into the canonical keep a changelog layout, see
+// Markdown. Everything before the first version, e.g. the title, is kept as it
+// is, and so are link reference definitions that aren't for a version. Since
+// Markdown only writes what Parse picks up, changelogs with anything else, e.g.
+// paragraphs between entries, are an error rather than losing it.
+func Format(changelog string, order SectionOrder) (string, error) {
+	changelogs, err := Parse("", changelog)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.ReplaceAll(changelog, "\r\n", "\n"), "\n")
+
+	root := markdownparser.New().Parse([]byte(changelog))
+	if problems := droppedContent(root, changelogs); len(problems) > 0 {
+		return "", fmt.Errorf(
+			"formatting would drop content:\n  %s",
+			strings.Join(problems, "\n  "),
+		)
+	}
+	keepVersionSpelling(root, changelogs)
+
+	var formatted strings.Builder
+	if preamble := strings.TrimRight(strings.Join(lines[:firstVersionLine(lines)], "\n"), "\n"); preamble != "" {
+		formatted.WriteString(preamble + "\n\n")
+	}
+	formatted.WriteString(Markdown(changelogs, order))
+
+	// Keep the definitions used by entries
+	versionLabels := map[string]bool{}
+	for _, versionChangelog := range changelogs {
+		version := strings.ToLower(normalizeVersion(versionChangelog.Version))
+		versionLabels[version] = true
+		versionLabels["v"+version] = true
+	}
+
+	var otherDefinitions []string
+	insideCodeFence := false
+	for _, line := range lines {
+		if codeFenceRegexp.MatchString(line) {
+			insideCodeFence = !insideCodeFence
+			continue
+		}
+
+		match := linkReferenceDefinitionRegexp.FindStringSubmatch(line)
+		if !insideCodeFence && match != nil && !versionLabels[strings.ToLower(match[1])] {
+			otherDefinitions = append(otherDefinitions, strings.TrimSpace(line))
+		}
+	}
+
+	if len(otherDefinitions) > 0 {
+		formatted.WriteString("\n" + strings.Join(otherDefinitions, "\n") + "\n")
+	}
+
+	return formatted.String(), nil
+}
+
+// firstVersionLine returns the index of the first version heading, or the
+// number of lines if there is none
+func firstVersionLine(lines []string) int {
+	insideCodeFence := false
+	for i, line := range lines {
+		if codeFenceRegexp.MatchString(line) {
+			insideCodeFence = !insideCodeFence
+			continue
+		}
+
+		if !insideCodeFence && versionHeadingRegexp.MatchString(line) {
+			return i
+		}
+	}
+
+	return len(lines)
+}
+
+// droppedContent describes what Markdown wouldn't write of a changelog:
+// anything after the first version other than version and section headings,
+// lists and thematic breaks, version headings without a version, blocks of
+// list items other than paragraphs and lists, and entries that aren't list
+// items of their own, e.g. those in a block quote
+func droppedContent(root ast.Node, changelogs []*VersionChangelog) []string {
+	var problems []string
+
+	insideVersions := false
+	for _, block := range root.GetChildren() {
+		if heading, ok := block.(*ast.Heading); ok && heading.Level == len("##") {
+			insideVersions = true
+
+			text := leafText(heading)
+			if !unreleasedRegexp.MatchString(text) && semverRegexp.FindStringSubmatch(text) == nil {
+				problems = append(problems, fmt.Sprintf("heading %q has no version", excerpt(text)))
+			}
+			continue
+		}
+
+		if !insideVersions {
+			continue
+		}
+
+		switch n := block.(type) {
+		case *ast.List:
+			problems = append(problems, droppedFromEntries(n)...)
+			continue
+		case *ast.HorizontalRule:
+			continue
+		case *ast.Heading:
+			if n.Level == len("###") {
+				continue
+			}
+		}
+
+		problems = append(problems, fmt.Sprintf("%s %q is not an entry", blockName(block), excerpt(leafText(block))))
+	}
+
+	var checkEntries func(entries []Entry)
+	checkEntries = func(entries []Entry) {
+		for _, entry := range entries {
+			switch {
+			case entry.Line == 0:
+				problems = append(problems, fmt.Sprintf("entry %q is not a list item of its own", excerpt(entry.Text)))
+			}
+
+			checkEntries(entry.Children)
+		}
+	}
+	for _, versionChangelog := range changelogs {
+		for _, section := range versionChangelog.Sections {
+			checkEntries(section.Entries)
+		}
+	}
+
+	return problems
+}
+
+// droppedFromEntries describes the blocks of list items that entries don't
+// keep, i.e. anything other than paragraphs and nested lists
+func droppedFromEntries(list *ast.List) []string {
+	var problems []string
+	for _, item := range listItems(list) {
+		for _, child := range item.GetChildren() {
+			switch child.(type) {
+			case *ast.Paragraph, *ast.List:
+				continue
+			}
+
+			problems = append(problems, fmt.Sprintf("%s %q in an entry is not part of it", blockName(child), excerpt(leafText(child))))
+		}
+	}
+
+	return problems
+}
+
+// keepVersionSpelling restores how the version headings spell versions, which
+// Parse normalizes: the build metadata, e.g. the `+suite.1` of `1.2.3+suite.1`,
+// and the `v` prefix if every version has one. Versions that disagree on the
+// prefix are written without it. It expects a version for every version
+// heading, as checked by droppedContent.
+func keepVersionSpelling(root ast.Node, changelogs []*VersionChangelog) {
+	var versions []*VersionChangelog
+	prefixed := 0
+
+	i := 0
+	for _, block := range root.GetChildren() {
+		heading, ok := block.(*ast.Heading)
+		if !ok || heading.Level != len("##") || i >= len(changelogs) {
+			continue
+		}
+
+		versionChangelog := changelogs[i]
+		i++
+		if versionChangelog.Unreleased {
+			continue
+		}
+
+		match := lintVersionRegexp.FindStringSubmatch(leafText(heading))
+		if match == nil {
+			continue
+		}
+		if match[2] != "" && normalizeVersion(match[1]) == versionChangelog.Version+match[2] {
+			versionChangelog.Version = normalizeVersion(match[1])
+		}
+
+		versions = append(versions, versionChangelog)
+		if strings.HasPrefix(strings.TrimPrefix(match[0], "["), "v") {
+			prefixed++
+		}
+	}
+
+	if prefixed > 0 && prefixed == len(versions) {
+		for _, versionChangelog := range versions {
+			versionChangelog.Version = "v" + versionChangelog.Version
+		}
+	}
+}
+
+// leafText concatenates the text of all leaves of a node
+func leafText(node ast.Node) string {
+	text := ""
+	ast.WalkFunc(node, func(node ast.Node, entering bool) ast.WalkStatus {
+		if leaf := node.AsLeaf(); leaf != nil && entering {
+			text += string(leaf.Literal)
+		}
+
+		return ast.GoToNext
+	})
+
+	return text
+}
+
+// blockName names a kind of markdown block for problem reports
+func blockName(block ast.Node) string {
+	switch block.(type) {
+	case *ast.Paragraph:
+		return "paragraph"
+	case *ast.BlockQuote:
+		return "block quote"
+	case *ast.CodeBlock:
+		return "code block"
+	case *ast.HTMLBlock:
+		return "HTML"
+	case *ast.Table:
+		return "table"
+	case *ast.Heading:
+		return "heading"
+	default:
+		return "block"
+	}
+}
+
+// excerpt shortens text to the start of its first line
+func excerpt(text string) string {
+	const maxLength = 40
+
+	text = strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+	if utf8.RuneCountInString(text) > maxLength {
+		text = string([]rune(text)[:maxLength]) + "…"
+	}
+
+	return text
+}
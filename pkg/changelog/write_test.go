@@ -0,0 +1,229 @@
+package changelog
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	expected := `## [Unreleased]
+
+### Added
+- Pending feature
+
+## [1.2.0] - 2020-01-29
+
+### Changed
+- Multi-line
+  change
+  - Nested change
+
+### Fixed
+- Fix
+
+[1.2.0]: https://github.com/cyberark/widget/compare/v1.1.0...v1.2.0
+`
+
+	actual := Markdown([]*VersionChangelog{
+		{
+			Version:    UnreleasedVersion,
+			Unreleased: true,
+			Sections:   []Section{{Name: "Added", Entries: entries("Pending feature")}},
+		},
+		{
+			Version:    "1.2.0",
+			Date:       "29.01.2020",
+			ParsedDate: time.Date(2020, time.January, 29, 0, 0, 0, 0, time.UTC),
+			URL:        "https://github.com/cyberark/widget/compare/v1.1.0...v1.2.0",
+			Sections: []Section{
+				{Name: "Fixed", Entries: entries("Fix")},
+				{
+					Name: "Changed",
+					Entries: []Entry{
+						{Text: "Multi-line change", Markdown: "Multi-line\nchange", Children: entries("Nested change")},
+					},
+				},
+			},
+		},
+	}, DefaultSectionOrder)
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestFormat(t *testing.T) {
+	changelog, err := ioutil.ReadFile("testdata/changelog.format.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+	expected, err := ioutil.ReadFile("testdata/changelog.format.expected.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	formatted, err := Format(string(changelog), DefaultSectionOrder)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, string(expected), formatted)
+
+	// Formatting is idempotent
+	reformatted, err := Format(formatted, DefaultSectionOrder)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, formatted, reformatted)
+}
+
+// entryPaths lists the entries of changelogs, including nested ones, as
+// `version/section/text` paths
+func entryPaths(changelogs []*VersionChangelog) []string {
+	var paths []string
+	var walk func(prefix string, entries []Entry)
+	walk = func(prefix string, entries []Entry) {
+		for _, entry := range entries {
+			paths = append(paths, prefix+entry.Text)
+			walk(prefix+entry.Text+"/", entry.Children)
+		}
+	}
+
+	for _, versionChangelog := range changelogs {
+		for _, section := range versionChangelog.Sections {
+			walk(versionChangelog.Version+"/"+section.Name+"/", section.Entries)
+		}
+	}
+
+	return paths
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, file := range []string{"changelog.format.md", "changelog.roundtrip.md", "changelog.nested.md"} {
+		t.Run(file, func(t *testing.T) {
+			changelog, err := ioutil.ReadFile("testdata/" + file)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			changelogs, err := Parse("", string(changelog))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			formatted, err := Format(string(changelog), DefaultSectionOrder)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			reparsed, err := Parse("", formatted)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.ElementsMatch(t, entryPaths(changelogs), entryPaths(reparsed))
+		})
+	}
+
+	t.Run("keeps build metadata", func(t *testing.T) {
+		changelog, err := ioutil.ReadFile("testdata/changelog.roundtrip.md")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		formatted, err := Format(string(changelog), DefaultSectionOrder)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Contains(t, formatted, "\n## [1.2.0+suite.1] - 2020-02-01\n")
+		assert.Contains(t, formatted, "- Loose entry\n\n    With a second paragraph\n")
+	})
+
+	t.Run("keeps the v prefix", func(t *testing.T) {
+		changelog := "# Changelog\n\n" +
+			"## [v1.1.0+suite.1] - 2020-02-01\n\n### Fixed\n- Fix\n\n" +
+			"## [v1.0.0] - 2020-01-01\n\n### Added\n- Feature\n\n" +
+			"[v1.1.0+suite.1]: https://github.com/cyberark/widget/compare/v1.0.0...v1.1.0\n" +
+			"[v1.0.0]: https://github.com/cyberark/widget/releases/tag/v1.0.0\n"
+
+		formatted, err := Format(changelog, DefaultSectionOrder)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, changelog, formatted)
+	})
+
+	t.Run("drops an inconsistent v prefix", func(t *testing.T) {
+		changelog := "## [v1.1.0] - 2020-02-01\n\n### Fixed\n- Fix\n\n" +
+			"## [1.0.0] - 2020-01-01\n\n### Added\n- Feature\n\n" +
+			"[v1.1.0]: https://github.com/cyberark/widget/compare/v1.0.0...v1.1.0\n"
+
+		formatted, err := Format(changelog, DefaultSectionOrder)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "## [1.1.0] - 2020-02-01\n\n### Fixed\n- Fix\n\n"+
+			"## [1.0.0] - 2020-01-01\n\n### Added\n- Feature\n\n"+
+			"[1.1.0]: https://github.com/cyberark/widget/compare/v1.0.0...v1.1.0\n", formatted)
+	})
+}
+
+func TestFormatRefusesToDropContent(t *testing.T) {
+	testData := []struct {
+		description string
+		changelog   string
+		problem     string
+	}{
+		{
+			description: "prose",
+			changelog:   "## 1.0.0\n### Changed\n- Entry\n\nMigration notes: run `migrate` first\n",
+			problem:     `paragraph "Migration notes: run migrate first" is not an entry`,
+		},
+		{
+			description: "heading without a version",
+			changelog:   "## 1.0.0\n### Changed\n- Entry\n\n## Next release\n### Added\n- Feature\n",
+			problem:     `heading "Next release" has no version`,
+		},
+		{
+			description: "commented out entry",
+			changelog:   "## 1.0.0\n### Changed\n<!--\n- Commented out\n-->\n- Entry\n",
+			problem:     `HTML "<!--" is not an entry`,
+		},
+		{
+			description: "quoted entry",
+			changelog:   "## 1.0.0\n### Changed\n- Entry\n\n> - Quoted\n",
+			problem:     `block quote "Quoted" is not an entry`,
+		},
+		{
+			description: "paragraph outside its list item",
+			changelog:   "## 1.0.0\n### Changed\n- Entry\n\n  Second paragraph\n",
+			problem:     `paragraph "Second paragraph" is not an entry`,
+		},
+		{
+			description: "indented code",
+			changelog:   "## 1.0.0\n### Changed\nExample:\n\n    - code\n",
+			problem:     `code block "- code" is not an entry`,
+		},
+		{
+			description: "code in an entry",
+			changelog:   "## 1.0.0\n### Changed\n- Entry with an example:\n\n    ```\n    conjur init\n    ```\n",
+			problem:     `code block "conjur init" in an entry is not part of it`,
+		},
+		{
+			description: "subheading",
+			changelog:   "## 1.0.0\n### Changed\n#### Details\n- Entry\n",
+			problem:     `heading "Details" is not an entry`,
+		},
+	}
+
+	for _, td := range testData {
+		t.Run(td.description, func(t *testing.T) {
+			_, err := Format(td.changelog, DefaultSectionOrder)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), "formatting would drop content:\n")
+				assert.Contains(t, err.Error(), td.problem)
+			}
+		})
+	}
+}
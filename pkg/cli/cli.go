@@ -1,8 +1,10 @@
 package cli
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/cyberark/conjur-oss-suite-release/pkg/github"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/provider"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/repositories"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/template"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/version"
@@ -18,11 +21,22 @@ import (
 // Options represents the command line values a user can pass in
 type Options struct {
 	APIToken           string
+	APIURL             string
+	CacheDir           string
+	CloneDir           string
+	Concurrency        int
 	Date               time.Time
+	DateFormat         string
+	NoCache            bool
 	OutputFilename     string
 	OutputType         string
+	RawURL             string
+	RecordFile         string
 	RepositoryFilename string
 	ReleasesDir        string
+	ReplayFile         string
+	SectionOrder       string
+	Timeout            time.Duration
 	Version            string
 }
 
@@ -43,11 +57,26 @@ var templates = map[string]templateInfo{
 		OutputFilename:      "ConjurSuite_%s.htm",
 		VersionInOutputName: true,
 	},
+	"impact": {
+		TemplateName:        "UPGRADE_IMPACT_unified.md.tmpl",
+		OutputFilename:      "UPGRADE_IMPACT_%s.md",
+		VersionInOutputName: true,
+	},
 	"release": {
 		TemplateName:        "RELEASE_NOTES_unified.md.tmpl",
 		OutputFilename:      "RELEASE_NOTES_%s.md",
 		VersionInOutputName: true,
 	},
+	"security": {
+		TemplateName:        "SECURITY_ADVISORIES_unified.md.tmpl",
+		OutputFilename:      "SECURITY_ADVISORIES_%s.md",
+		VersionInOutputName: true,
+	},
+	"security-json": {
+		TemplateName:        "SECURITY_ADVISORIES_unified.json.tmpl",
+		OutputFilename:      "SECURITY_ADVISORIES_%s.json",
+		VersionInOutputName: true,
+	},
 	"unreleased": {
 		TemplateName:        "UNRELEASED_CHANGES_unified.md.tmpl",
 		OutputFilename:      "UNRELEASED.md",
@@ -65,7 +94,8 @@ const defaultVersionString = "Unreleased"
 // 2. Collect data on each component as specified
 // 3. Build a unified changelog with each component
 // 4. Write a new changelog based on the appropriate template
-func RunParser(options Options) error {
+// Cancelling the context aborts any in-flight requests.
+func RunParser(ctx context.Context, options Options) error {
 	log.OutLogger.Printf("Parsing linked repositories...")
 	repoConfig, err := repositories.NewConfig(options.RepositoryFilename)
 	if err != nil {
@@ -93,34 +123,90 @@ func RunParser(options Options) error {
 		repoConfig.SetBaselineRepoVersions(&previousReleaseConfig)
 	}
 
-	log.OutLogger.Printf("Collecting changelogs...")
-	httpClient := http.NewClient()
+	configureSources(&repoConfig, options.APIURL, options.RawURL, options.CloneDir)
 
-	githubAPIToken := options.APIToken
-	if len(githubAPIToken) == 0 {
-		githubAPIToken = os.Getenv("GITHUB_TOKEN")
+	log.OutLogger.Printf("Collecting changelogs...")
+	httpClient, err := newHTTPClient(
+		repoConfig,
+		options.APIToken,
+		options.ReplayFile,
+		options.CloneDir,
+		options.Timeout,
+	)
+	if err != nil {
+		return err
 	}
-	if len(githubAPIToken) == 0 {
-		log.ErrLogger.Printf("WARN: No Github API token specified (via %q flag or %q environment variable). This run might FAIL due to the API rate limit", "-p", "GITHUB_TOKEN")
+
+	var recorder *http.Recorder
+	if options.RecordFile != "" {
+		recorder = http.NewRecorder(httpClient.Transport)
+		httpClient.Transport = recorder
 	}
 
-	httpClient.AuthToken = githubAPIToken
+	// Report how much of the API rate limit this run used, even if it failed
+	defer func() {
+		if rateLimit, ok := httpClient.RateLimit(); ok {
+			log.OutLogger.Printf("GitHub API rate limit: %s", rateLimit)
+		}
+	}()
+
+	// Cache responses on disk so that subsequent runs only need cheap
+	// conditional requests. Cassettes need the real responses so the cache is
+	// bypassed when recording or replaying.
+	var collectionClient http.IClient = httpClient
+	usingCassette := options.RecordFile != "" || options.ReplayFile != ""
+	if !options.NoCache && !usingCassette && options.CacheDir != "" {
+		log.OutLogger.Printf("Caching HTTP responses in %s", options.CacheDir)
+		collectionClient = http.NewCachingClient(httpClient, options.CacheDir)
+	}
 
-	suiteCategories, err := github.CollectSuiteCategories(repoConfig, httpClient, options.Version)
+	suiteCategories, err := github.CollectSuiteCategories(
+		ctx,
+		repoConfig,
+		collectionClient,
+		options.Version,
+		options.Concurrency,
+	)
 	if err != nil {
 		return fmt.Errorf("ERROR: %v", err)
 	}
 
+	if recorder != nil {
+		log.OutLogger.Printf("Recording HTTP responses to %s", options.RecordFile)
+		err = recorder.Cassette().Save(options.RecordFile)
+		if err != nil {
+			return fmt.Errorf("error saving cassette: %v", err)
+		}
+	}
+
 	// Combine all changelogs into a single array to generate the unified changelog
+	// and give the sections of each one their canonical names and order
+	order := sectionOrder(options, repoConfig)
+	layout := dateFormat(options, repoConfig)
+	aliases := changelog.NewSectionAliases(repoConfig.Section.SectionAliases)
 	changelogs := []*changelog.VersionChangelog{}
+	var classifications []changelog.Classification
 	for _, category := range suiteCategories {
-		for _, component := range category.Components {
+		for componentIndex := range category.Components {
+			// Release dates are updated in place
+			component := &category.Components[componentIndex]
+			formatDates(component, layout)
+
+			for _, versionChangelog := range component.Changelogs {
+				classifications = append(classifications, normalizeSections(versionChangelog, aliases, order)...)
+			}
+			if component.UnreleasedChangelog != nil {
+				classifications = append(classifications, normalizeSections(component.UnreleasedChangelog, aliases, order)...)
+			}
+
 			if component.Changelogs != nil {
 				changelogs = append(changelogs, component.Changelogs...)
 			}
 		}
 	}
-	unifiedChangelog := changelog.NewUnifiedChangelog(changelogs...)
+	reportClassifications(classifications)
+
+	unifiedChangelog := changelog.NewUnifiedChangelog(order, changelogs...)
 
 	// TODO: Should the date be something defined in yml or the date of tag?
 	if options.Date.IsZero() {
@@ -131,9 +217,15 @@ func RunParser(options Options) error {
 		// TODO: Suite version should probably be read from some file
 		Version:          options.Version,
 		Date:             options.Date,
+		DateFormat:       layout,
 		Description:      repoConfig.Section.Description,
 		SuiteCategories:  suiteCategories,
 		UnifiedChangelog: unifiedChangelog.String(),
+		SuiteChangelog: changelog.Markdown(
+			[]*changelog.VersionChangelog{unifiedChangelog.VersionChangelog(options.Version, options.Date)},
+			order,
+		),
+		Changes: unifiedChangelog,
 	}
 
 	tmpl := template.New("templates")
@@ -148,6 +240,170 @@ func RunParser(options Options) error {
 	return err
 }
 
+// configureSources points the repos of a config at e.g. a GitHub Enterprise
+// instance or local clones, as requested on the command line
+func configureSources(repoConfig *repositories.Config, apiURL string, rawURL string, cloneDir string) {
+	if apiURL == "" {
+		apiURL = os.Getenv("GITHUB_API_URL")
+	}
+	if rawURL == "" {
+		rawURL = os.Getenv("GITHUB_RAW_URL")
+	}
+	if apiURL != "" || rawURL != "" {
+		log.OutLogger.Printf("Using GitHub API URL %q and raw content URL %q", apiURL, rawURL)
+		repoConfig.SetDefaultGitHubURLs(apiURL, rawURL)
+	}
+
+	if cloneDir != "" {
+		log.OutLogger.Printf("Reading all repos from the local clones in %s", cloneDir)
+		repoConfig.UseLocalClones(cloneDir)
+	}
+}
+
+// newHTTPClient creates the client that repos are fetched with. It replays the
+// cassette if one is given and otherwise authenticates to GitHub.
+func newHTTPClient(
+	repoConfig repositories.Config,
+	apiToken string,
+	replayFile string,
+	cloneDir string,
+	timeout time.Duration,
+) (*http.Client, error) {
+	httpClient := http.NewClient()
+
+	if replayFile != "" {
+		// Replayed runs never touch the network so they need no token
+		log.OutLogger.Printf("Replaying HTTP responses from %s", replayFile)
+		cassette, err := http.LoadCassette(replayFile)
+		if err != nil {
+			return nil, err
+		}
+		httpClient = http.NewReplayingClient(cassette)
+	} else if cloneDir == "" {
+		// Local clones don't need a token either
+		githubAPIToken := apiToken
+		if len(githubAPIToken) == 0 {
+			githubAPIToken = os.Getenv("GITHUB_TOKEN")
+		}
+		if len(githubAPIToken) == 0 {
+			log.ErrLogger.Printf("WARN: No Github API token specified (via %q flag or %q environment variable). This run might FAIL due to the API rate limit", "-p", "GITHUB_TOKEN")
+		}
+
+		// Only GitHub should ever see the GitHub token
+		httpClient.AuthToken = githubAPIToken
+		httpClient.AuthHosts = githubAuthHosts(repoConfig)
+	}
+
+	if timeout > 0 {
+		httpClient.Timeout = timeout
+	}
+
+	return httpClient, nil
+}
+
+// sectionOrder returns the order of changelog sections, taken from the options,
+// the suite config or otherwise the Keep a Changelog default
+func sectionOrder(options Options, repoConfig repositories.Config) changelog.SectionOrder {
+	if order := changelog.ParseSectionOrder(options.SectionOrder); len(order) > 0 {
+		return order
+	}
+
+	if len(repoConfig.Section.SectionOrder) > 0 {
+		return changelog.SectionOrder(repoConfig.Section.SectionOrder)
+	}
+
+	return changelog.DefaultSectionOrder
+}
+
+// dateFormat returns the layout of the dates in the release notes, taken from
+// the options, the suite config or otherwise the Keep a Changelog default
+func dateFormat(options Options, repoConfig repositories.Config) string {
+	if options.DateFormat != "" {
+		return options.DateFormat
+	}
+
+	if repoConfig.Section.DateFormat != "" {
+		return repoConfig.Section.DateFormat
+	}
+
+	return changelog.DefaultDateFormat
+}
+
+// formatDates shows the dates of a component's changelogs, and so its release
+// date, in a layout. Dates that couldn't be parsed are left as written.
+func formatDates(component *github.SuiteComponent, layout string) {
+	for _, versionChangelog := range component.Changelogs {
+		date := versionChangelog.FormatDate(layout)
+		if component.ReleaseDate != "" && component.ReleaseDate == versionChangelog.Date {
+			component.ReleaseDate = date
+		}
+		versionChangelog.Date = date
+	}
+}
+
+// normalizeSections renames the sections of a changelog to their canonical
+// names, classifies the entries that aren't in any section and sorts the
+// sections. Sections with unknown names are kept but reported.
+func normalizeSections(
+	versionChangelog *changelog.VersionChangelog,
+	aliases changelog.SectionAliases,
+	order changelog.SectionOrder,
+) []changelog.Classification {
+	for _, name := range aliases.Normalize(versionChangelog) {
+		log.ErrLogger.Printf(
+			"WARN: Unknown changelog section %q in %s@%s",
+			name,
+			versionChangelog.Repo,
+			versionChangelog.Version,
+		)
+	}
+
+	classifications := changelog.Classify(versionChangelog)
+	order.SortSections(versionChangelog.Sections)
+
+	return classifications
+}
+
+// reportClassifications logs which section each entry without one was put in,
+// so that they can be double-checked
+func reportClassifications(classifications []changelog.Classification) {
+	if len(classifications) == 0 {
+		return
+	}
+
+	log.OutLogger.Printf("Classified %d changelog entries without a section:", len(classifications))
+	for _, classification := range classifications {
+		log.OutLogger.Printf(
+			"- %s@%s: %s: %s",
+			classification.Repo,
+			classification.Version,
+			classification.Section,
+			classification.Entry.Text,
+		)
+	}
+}
+
+// githubAuthHosts lists every host that GitHub-hosted repos of a config are
+// fetched from
+func githubAuthHosts(repoConfig repositories.Config) []string {
+	hosts := append([]string{}, provider.GitHubAuthHosts...)
+	for _, category := range repoConfig.Section.Categories {
+		for _, repo := range category.Repos {
+			if !provider.IsGitHub(repo.Provider) {
+				continue
+			}
+
+			for _, baseURL := range []string{repo.APIURL, repo.RawURL} {
+				if parsedURL, err := url.Parse(baseURL); err == nil && parsedURL.Hostname() != "" {
+					hosts = append(hosts, parsedURL.Hostname())
+				}
+			}
+		}
+	}
+
+	return hosts
+}
+
 // HandleInput parses command line values and stores them within an options struct
 func (options *Options) HandleInput() error {
 	flag.StringVar(&options.RepositoryFilename, "f", defaultRepositoryFilename,
@@ -156,13 +412,40 @@ func (options *Options) HandleInput() error {
 		"Directory of releases (containinng 'suite_<semver>.yml') files. "+
 			"Set this to empty string to skip suite version diffing.")
 	flag.StringVar(&options.OutputType, "t", defaultOutputType,
-		"Output type. Only accepts 'changelog', 'docs-release', 'release', and 'unreleased'.")
+		"Output type. Only accepts 'changelog', 'docs-release', 'impact', 'release', 'security', 'security-json', and 'unreleased'.")
 	flag.StringVar(&options.OutputFilename, "o", "",
 		"Output filename")
 	flag.StringVar(&options.Version, "v", defaultVersionString,
 		"Version to embed in the changelog")
 	flag.StringVar(&options.APIToken, "p", "",
 		"GitHub API token. This can also be passed in as the 'GITHUB_TOKEN' environment variable. The flag takes precedence.")
+	flag.StringVar(&options.APIURL, "api-url", "",
+		"Base URL of the GitHub API, e.g. 'https://ghe.example.com/api/v3' for GitHub Enterprise. "+
+			"This can also be passed in as the 'GITHUB_API_URL' environment variable. The flag takes precedence.")
+	flag.StringVar(&options.RawURL, "raw-url", "",
+		"Base URL of raw GitHub file contents. If only a custom API URL is set, files are fetched through the API instead. "+
+			"This can also be passed in as the 'GITHUB_RAW_URL' environment variable. The flag takes precedence.")
+	flag.StringVar(&options.CloneDir, "clones-dir", "",
+		"Read tags and CHANGELOGs of every repo from the git clones in this directory "+
+			"(as '<dir>/<org>/<repo>' or '<dir>/<repo>') instead of using the network")
+	flag.StringVar(&options.SectionOrder, "section-order", "",
+		"Comma-separated order of changelog sections, e.g. 'Security,Fixed,Added'. "+
+			"Defaults to the 'section_order' of the repository YAML file, or else the Keep a Changelog order.")
+	flag.StringVar(&options.DateFormat, "date-format", "",
+		"Go time layout of the dates in the release notes, e.g. 'January 2, 2006'. "+
+			"Defaults to the 'date_format' of the repository YAML file, or else '2006-01-02'.")
+	flag.IntVar(&options.Concurrency, "j", github.DefaultConcurrency,
+		"Maximum number of repositories to collect data for at the same time")
+	flag.StringVar(&options.CacheDir, "cache-dir", http.DefaultCacheDir(),
+		"Directory in which HTTP responses are cached between runs")
+	flag.BoolVar(&options.NoCache, "no-cache", false,
+		"Disable the HTTP response cache")
+	flag.StringVar(&options.RecordFile, "record", "",
+		"Record all HTTP responses to this cassette file")
+	flag.StringVar(&options.ReplayFile, "replay", "",
+		"Replay HTTP responses from this cassette file instead of using the network")
+	flag.DurationVar(&options.Timeout, "timeout", http.DefaultTimeout,
+		"Time limit for each HTTP request (e.g. '30s', '2m')")
 	flag.Parse()
 
 	err := options.setOutputFilename()
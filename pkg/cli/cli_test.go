@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,13 +12,13 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/github"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/repositories"
 )
 
 func TestRunParser(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode.")
-	}
-
 	// Construct a path to our test repositories yaml
 	thisDir, err := os.Getwd()
 	if !assert.NoError(t, err) {
@@ -23,6 +26,7 @@ func TestRunParser(t *testing.T) {
 	}
 
 	testRepositoriesYml := filepath.Join(thisDir, "testdata", "suite.yml")
+	testCassette := filepath.Join(thisDir, "testdata", "cassettes", "github.yml")
 
 	// We have to run from toplevel dir to be able to use the defaults
 	os.Chdir("../..")
@@ -30,7 +34,7 @@ func TestRunParser(t *testing.T) {
 		os.Chdir(thisDir)
 	}()
 
-	for _, tt := range []string{"changelog", "docs-release", "release"} {
+	for _, tt := range []string{"changelog", "docs-release", "impact", "release", "security"} {
 		t.Run(tt, func(t *testing.T) {
 			// Create a tempdir to write the out output to
 			outputDir, err := ioutil.TempDir("", "main_test")
@@ -43,10 +47,11 @@ func TestRunParser(t *testing.T) {
 			outputDate, _ := time.Parse(time.RFC3339, "2020-02-19T12:00:00Z")
 
 			// Run the test
-			err = RunParser(Options{
+			err = RunParser(context.Background(), Options{
 				Date:               outputDate,
 				OutputFilename:     outputFile,
 				OutputType:         tt,
+				ReplayFile:         testCassette,
 				RepositoryFilename: testRepositoriesYml,
 				Version:            "Unreleased",
 			})
@@ -72,10 +77,6 @@ func TestRunParser(t *testing.T) {
 }
 
 func TestRunParserWithReleaseDiffing(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode.")
-	}
-
 	// Construct a path to our test repositories yaml
 	thisDir, err := os.Getwd()
 	if !assert.NoError(t, err) {
@@ -83,6 +84,7 @@ func TestRunParserWithReleaseDiffing(t *testing.T) {
 	}
 
 	testRepositoriesYml := filepath.Join(thisDir, "testdata", "new_release_suite.yml")
+	testCassette := filepath.Join(thisDir, "testdata", "cassettes", "github.yml")
 
 	// We have to run from toplevel dir to be able to use the defaults
 	os.Chdir("../..")
@@ -97,16 +99,17 @@ func TestRunParserWithReleaseDiffing(t *testing.T) {
 	}
 	defer os.RemoveAll(outputDir)
 
-	for _, tt := range []string{"changelog", "docs-release", "release"} {
+	for _, tt := range []string{"changelog", "docs-release", "impact", "release", "security"} {
 		t.Run(tt, func(t *testing.T) {
 			outputFile := filepath.Join(outputDir, tt+"output.txt")
 			outputDate, _ := time.Parse(time.RFC3339, "2020-02-19T12:00:00Z")
 
 			// Run the test
-			err = RunParser(Options{
+			err = RunParser(context.Background(), Options{
 				Date:               outputDate,
 				OutputFilename:     outputFile,
 				OutputType:         tt,
+				ReplayFile:         testCassette,
 				RepositoryFilename: testRepositoriesYml,
 				ReleasesDir:        filepath.Join(thisDir, "testdata", "mock_releases"),
 				Version:            "Unreleased",
@@ -208,3 +211,310 @@ func TestSettingOutputFilename(t *testing.T) {
 		})
 	}
 }
+
+func TestGitHubAuthHosts(t *testing.T) {
+	repoConfig, err := repositories.NewConfig(filepath.Join("testdata", "ghe_suite.yml"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(
+		t,
+		[]string{"api.github.com", "raw.githubusercontent.com", "ghe.example.com", "raw.ghe.example.com"},
+		githubAuthHosts(repoConfig),
+	)
+
+	repoConfig.SetDefaultGitHubURLs("https://other-ghe.example.com/api/v3", "")
+	assert.Contains(t, githubAuthHosts(repoConfig), "other-ghe.example.com")
+}
+
+func TestSectionOrder(t *testing.T) {
+	repoConfig := repositories.Config{}
+	assert.Equal(t, changelog.DefaultSectionOrder, sectionOrder(Options{}, repoConfig))
+
+	repoConfig.Section.SectionOrder = []string{"Security", "Fixed"}
+	assert.Equal(t, changelog.SectionOrder{"Security", "Fixed"}, sectionOrder(Options{}, repoConfig))
+
+	options := Options{SectionOrder: "Fixed, Added"}
+	assert.Equal(t, changelog.SectionOrder{"Fixed", "Added"}, sectionOrder(options, repoConfig))
+}
+
+func TestDateFormat(t *testing.T) {
+	repoConfig := repositories.Config{}
+	assert.Equal(t, "2006-01-02", dateFormat(Options{}, repoConfig))
+
+	repoConfig.Section.DateFormat = "02.01.2006"
+	assert.Equal(t, "02.01.2006", dateFormat(Options{}, repoConfig))
+
+	options := Options{DateFormat: "January 2, 2006"}
+	assert.Equal(t, "January 2, 2006", dateFormat(options, repoConfig))
+}
+
+func TestFormatDates(t *testing.T) {
+	released := &changelog.VersionChangelog{Version: "1.2.3", Date: "29.01.2020"}
+	released.ParsedDate, _ = changelog.ParseDate(released.Date)
+	ambiguous := &changelog.VersionChangelog{Version: "1.2.2", Date: "03/04/20"}
+
+	component := &github.SuiteComponent{
+		ReleaseName: "v1.2.3",
+		ReleaseDate: "29.01.2020",
+		Changelogs:  []*changelog.VersionChangelog{released, ambiguous},
+	}
+	formatDates(component, "January 2, 2006")
+
+	assert.Equal(t, "January 29, 2020", released.Date)
+	assert.Equal(t, "03/04/20", ambiguous.Date)
+	assert.Equal(t, "January 29, 2020", component.ReleaseDate)
+}
+
+func TestNormalizeSections(t *testing.T) {
+	versionChangelog := &changelog.VersionChangelog{
+		Repo:    "cyberark/conjur",
+		Version: "1.2.3",
+		Sections: []changelog.Section{
+			{Name: "Bug Fixes", Entries: []changelog.Entry{{Text: "fix 1"}}},
+			{Name: "Known Issues", Entries: []changelog.Entry{{Text: "issue 1"}}},
+			{Name: "New Features", Entries: []changelog.Entry{{Text: "add 1"}}},
+			{Name: "Fix", Entries: []changelog.Entry{{Text: "fix 2"}}},
+			{Name: "Maintenance", Entries: []changelog.Entry{{Text: "chore 1"}}},
+			{Name: "", Entries: []changelog.Entry{{Text: "Fixed fix 3"}, {Text: "misc 1"}}},
+		},
+	}
+
+	aliases := changelog.NewSectionAliases(map[string]string{"Maintenance": "Changed"})
+	classifications := normalizeSections(versionChangelog, aliases, changelog.DefaultSectionOrder)
+
+	assert.Equal(t, []changelog.Section{
+		{Name: "Added", Entries: []changelog.Entry{{Text: "add 1"}}},
+		{Name: "Changed", Entries: []changelog.Entry{{Text: "chore 1"}}},
+		{Name: "Fixed", Entries: []changelog.Entry{{Text: "fix 1"}, {Text: "fix 2"}, {Text: "Fixed fix 3"}}},
+		{Name: "Known Issues", Entries: []changelog.Entry{{Text: "issue 1"}}},
+		{Name: "Other", Entries: []changelog.Entry{{Text: "misc 1"}}},
+	}, versionChangelog.Sections)
+	assert.Len(t, classifications, 2)
+}
+
+func TestRunLint(t *testing.T) {
+	t.Run("files", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := RunLint(context.Background(), LintOptions{
+			ReportOptions: ReportOptions{
+				Files:  []string{"../changelog/testdata/changelog.lint.md"},
+				Format: "json",
+			},
+		}, out)
+		assert.EqualError(t, err, "found 11 changelog problem(s)")
+
+		var diagnostics []changelog.Diagnostic
+		if !assert.NoError(t, json.Unmarshal(out.Bytes(), &diagnostics)) {
+			return
+		}
+		if assert.Len(t, diagnostics, 11) {
+			assert.Equal(t, changelog.Diagnostic{
+				File:    "../changelog/testdata/changelog.lint.md",
+				Line:    5,
+				Rule:    changelog.RuleEntryOutsideSection,
+				Message: "entry is not under a ### section",
+			}, diagnostics[0])
+		}
+	})
+
+	t.Run("suite", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := RunLint(context.Background(), LintOptions{
+			ReportOptions: ReportOptions{
+				Format:             "text",
+				RepositoryFilename: filepath.Join("testdata", "suite.yml"),
+				ReplayFile:         filepath.Join("testdata", "cassettes", "github.yml"),
+			},
+		}, out)
+		assert.NoError(t, err)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("suite with a repo without a CHANGELOG", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := RunLint(context.Background(), LintOptions{
+			ReportOptions: ReportOptions{
+				Format:             "text",
+				RepositoryFilename: filepath.Join("testdata", "commits_suite.yml"),
+				ReplayFile:         filepath.Join("testdata", "cassettes", "github.yml"),
+			},
+		}, out)
+		assert.NoError(t, err)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("files with configured section aliases", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "lint_test")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer os.RemoveAll(dir)
+
+		file := filepath.Join(dir, "CHANGELOG.md")
+		contents := "# Changelog\n\n## 1.0.0 - 2020-01-01\n### Known Issues\n- Something\n"
+		if !assert.NoError(t, ioutil.WriteFile(file, []byte(contents), 0644)) {
+			return
+		}
+
+		for _, td := range []struct {
+			repositoryFilename string
+			expected           string
+		}{
+			{filepath.Join("testdata", "commits_suite.yml"), ""},
+			{filepath.Join(dir, "missing.yml"), file + `:4: non-standard section name "Known Issues" (expected one of: Added, Changed, Deprecated, Removed, Fixed, Security) (section-name)` + "\n"},
+		} {
+			out := &bytes.Buffer{}
+			err := RunLint(context.Background(), LintOptions{
+				ReportOptions: ReportOptions{
+					Files:              []string{file},
+					Format:             "text",
+					RepositoryFilename: td.repositoryFilename,
+				},
+			}, out)
+			assert.Equal(t, td.expected == "", err == nil)
+			assert.Equal(t, td.expected, out.String())
+		}
+	})
+
+	t.Run("bad format", func(t *testing.T) {
+		err := RunLint(context.Background(), LintOptions{
+			ReportOptions: ReportOptions{
+				Files:  []string{"../changelog/testdata/changelog.simple.md"},
+				Format: "xml",
+			},
+		}, &bytes.Buffer{})
+		assert.EqualError(t, err, "xml is not a valid lint format")
+	})
+}
+
+func TestRunSemver(t *testing.T) {
+	t.Run("files", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := RunSemver(context.Background(), SemverOptions{
+			ReportOptions: ReportOptions{
+				Files:  []string{"../changelog/testdata/changelog.semver.md"},
+				Format: "text",
+			},
+		}, out)
+		assert.NoError(t, err)
+		assert.Equal(t, `../changelog/testdata/changelog.semver.md@1.1.1: patch release after 1.1.0 has "Added" and "Deprecated" entries, which need a minor release (feature-in-patch)
+../changelog/testdata/changelog.semver.md@1.2.0: has "Removed" entries but isn't a major release after 1.1.1 (breaking-without-major)
+../changelog/testdata/changelog.semver.md@3.0.0: major release after 2.0.0 has no breaking changes or removals (major-without-breaking)
+`, out.String())
+	})
+
+	t.Run("strict suite", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := RunSemver(context.Background(), SemverOptions{
+			ReportOptions: ReportOptions{
+				Format:             "json",
+				RepositoryFilename: filepath.Join("testdata", "suite.yml"),
+				ReplayFile:         filepath.Join("testdata", "cassettes", "github.yml"),
+			},
+			Strict:  true,
+			Version: "Unreleased",
+		}, out)
+		assert.EqualError(t, err, "found 4 semver violation(s)")
+
+		var violations []changelog.SemverViolation
+		if !assert.NoError(t, json.Unmarshal(out.Bytes(), &violations)) {
+			return
+		}
+		if assert.Len(t, violations, 4) {
+			assert.Equal(t, changelog.SemverViolation{
+				Repo:    "cyberark/conjur-api-java",
+				Version: "2.0.0",
+				Rule:    changelog.RuleMajorWithoutBreaking,
+				Message: "major release after 1.1.0 has no breaking changes or removals",
+			}, violations[3])
+		}
+	})
+
+	t.Run("bad format", func(t *testing.T) {
+		err := RunSemver(context.Background(), SemverOptions{
+			ReportOptions: ReportOptions{
+				Files:  []string{"../changelog/testdata/changelog.semver.md"},
+				Format: "xml",
+			},
+		}, &bytes.Buffer{})
+		assert.EqualError(t, err, "xml is not a valid semver format")
+	})
+}
+
+func TestRunFmt(t *testing.T) {
+	input := filepath.Join("..", "changelog", "testdata", "changelog.format.md")
+	expected, err := ioutil.ReadFile(filepath.Join("..", "changelog", "testdata", "changelog.format.expected.md"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("stdout", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := RunFmt(FmtOptions{Files: []string{input}}, out)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, string(expected), out.String())
+	})
+
+	t.Run("write", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "fmt_test")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer os.RemoveAll(dir)
+
+		contents, err := ioutil.ReadFile(input)
+		if !assert.NoError(t, err) {
+			return
+		}
+		file := filepath.Join(dir, "CHANGELOG.md")
+		if !assert.NoError(t, ioutil.WriteFile(file, contents, 0644)) {
+			return
+		}
+
+		out := &bytes.Buffer{}
+		err = RunFmt(FmtOptions{Files: []string{file}, Write: true}, out)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Empty(t, out.String())
+
+		actual, err := ioutil.ReadFile(file)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, string(expected), string(actual))
+	})
+
+	t.Run("won't drop content", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "fmt_test")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer os.RemoveAll(dir)
+
+		contents := "## 1.0.0\n### Changed\n- Entry\n\nMigration notes\n"
+		file := filepath.Join(dir, "CHANGELOG.md")
+		if !assert.NoError(t, ioutil.WriteFile(file, []byte(contents), 0644)) {
+			return
+		}
+
+		err = RunFmt(FmtOptions{Files: []string{file}, Write: true}, &bytes.Buffer{})
+		assert.EqualError(t, err, file+": formatting would drop content:\n  paragraph \"Migration notes\" is not an entry")
+
+		actual, err := ioutil.ReadFile(file)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, contents, string(actual))
+	})
+
+	t.Run("no files", func(t *testing.T) {
+		err := RunFmt(FmtOptions{}, &bytes.Buffer{})
+		assert.EqualError(t, err, "no CHANGELOG files given")
+	})
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+)
+
+// FmtCommand is the name of the subcommand that reformats changelogs
+const FmtCommand = "fmt"
+
+// FmtOptions represents the command line values of the fmt command
+type FmtOptions struct {
+	Files        []string
+	SectionOrder string
+	Write        bool
+}
+
+// RunFmt reformats CHANGELOG files into the canonical keep a changelog layout.
+// The result is written to `out` unless the files are to be overwritten.
+func RunFmt(options FmtOptions, out io.Writer) error {
+	if len(options.Files) == 0 {
+		return fmt.Errorf("no CHANGELOG files given")
+	}
+
+	order := changelog.ParseSectionOrder(options.SectionOrder)
+	if len(order) == 0 {
+		order = changelog.DefaultSectionOrder
+	}
+
+	for _, file := range options.Files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		formatted, err := changelog.Format(string(contents), order)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+
+		if !options.Write {
+			_, err = io.WriteString(out, formatted)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if formatted == string(contents) {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		log.OutLogger.Printf("Formatting %s", file)
+		err = ioutil.WriteFile(file, []byte(formatted), info.Mode())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HandleInput parses the command line values of the fmt command, i.e. the
+// arguments following `fmt`, and stores them within a fmt options struct
+func (options *FmtOptions) HandleInput(args []string) error {
+	flags := flag.NewFlagSet(FmtCommand, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [flags] CHANGELOG.md ...\n", filepath.Base(os.Args[0]), FmtCommand)
+		flags.PrintDefaults()
+	}
+
+	flags.BoolVar(&options.Write, "w", false,
+		"Overwrite the files instead of writing the result to stdout")
+	flags.StringVar(&options.SectionOrder, "section-order", "",
+		"Comma-separated order of changelog sections, e.g. 'Security,Fixed,Added'. Defaults to the Keep a Changelog order.")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	options.Files = flags.Args()
+
+	return nil
+}
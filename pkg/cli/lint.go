@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/github"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/repositories"
+)
+
+// LintCommand is the name of the subcommand that lints changelogs
+const LintCommand = "lint"
+
+// LintOptions represents the command line values of the lint command
+type LintOptions struct {
+	ReportOptions
+}
+
+// RunLint checks changelogs against the keep a changelog format and writes the
+// problems found to `out`. The changelogs are the local files of the options
+// or, if there are none, the CHANGELOGs of every repo in the repository YAML
+// file. Section names may use the aliases of the repository YAML file, if it
+// exists. Finding any problem is an error so that CI can gate on it.
+func RunLint(ctx context.Context, options LintOptions, out io.Writer) error {
+	var diagnostics []changelog.Diagnostic
+
+	if len(options.Files) > 0 {
+		aliases, err := configuredSectionAliases(options.RepositoryFilename)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range options.Files {
+			contents, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			diagnostics = append(diagnostics, changelog.Lint(file, string(contents), aliases)...)
+		}
+	} else {
+		repoConfig, err := repositories.NewConfig(options.RepositoryFilename)
+		if err != nil {
+			return err
+		}
+
+		configureSources(&repoConfig, options.APIURL, options.RawURL, options.CloneDir)
+
+		httpClient, err := newHTTPClient(
+			repoConfig,
+			options.APIToken,
+			options.ReplayFile,
+			options.CloneDir,
+			options.Timeout,
+		)
+		if err != nil {
+			return err
+		}
+
+		repoDiagnostics, err := lintRepos(ctx, repoConfig, httpClient)
+		if err != nil {
+			return err
+		}
+		diagnostics = append(diagnostics, repoDiagnostics...)
+	}
+
+	err := writeDiagnostics(out, options.Format, diagnostics)
+	if err != nil {
+		return err
+	}
+
+	if len(diagnostics) > 0 {
+		return fmt.Errorf("found %d changelog problem(s)", len(diagnostics))
+	}
+
+	return nil
+}
+
+// configuredSectionAliases returns the section aliases of a repository YAML
+// file, or the default ones if there is no such file
+func configuredSectionAliases(repositoryFilename string) (changelog.SectionAliases, error) {
+	if _, err := os.Stat(repositoryFilename); os.IsNotExist(err) {
+		return changelog.DefaultSectionAliases, nil
+	}
+
+	repoConfig, err := repositories.NewConfig(repositoryFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	return changelog.NewSectionAliases(repoConfig.Section.SectionAliases), nil
+}
+
+// lintRepos lints the CHANGELOG on the default branch of every repo of a config.
+// Diagnostics are labelled `<repo>/CHANGELOG.md`. Repos whose changelogs come
+// from their commits have no CHANGELOG to lint.
+func lintRepos(
+	ctx context.Context,
+	repoConfig repositories.Config,
+	httpClient http.IClient,
+) ([]changelog.Diagnostic, error) {
+	aliases := changelog.NewSectionAliases(repoConfig.Section.SectionAliases)
+
+	var diagnostics []changelog.Diagnostic
+	for _, category := range repoConfig.Section.Categories {
+		for _, repo := range category.Repos {
+			if repo.ChangelogSource == repositories.ChangelogSourceCommits {
+				log.OutLogger.Printf("- Skipping repo: %s, since it has no CHANGELOG", repo.Name)
+				continue
+			}
+
+			log.OutLogger.Printf("- Linting repo: %s", repo.Name)
+
+			contents, err := github.FetchChangelog(ctx, httpClient, repo)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", repo.Name, err)
+			}
+
+			diagnostics = append(diagnostics, changelog.Lint(repo.Name+"/CHANGELOG.md", contents, aliases)...)
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// writeDiagnostics writes diagnostics one per line, or as a JSON array
+func writeDiagnostics(out io.Writer, format string, diagnostics []changelog.Diagnostic) error {
+	switch format {
+	case "json":
+		if diagnostics == nil {
+			diagnostics = []changelog.Diagnostic{}
+		}
+
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diagnostics)
+	case "text":
+		for _, diagnostic := range diagnostics {
+			_, err := fmt.Fprintln(out, diagnostic)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s is not a valid lint format", format)
+	}
+}
+
+// HandleInput parses the command line values of the lint command, i.e. the
+// arguments following `lint`, and stores them within a lint options struct
+func (options *LintOptions) HandleInput(args []string) error {
+	flags := options.newReportFlagSet(LintCommand,
+		"Repository YAML file whose repos are linted if no files are given, and whose section aliases are allowed")
+
+	return options.parseReportFlags(flags, args)
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+// defaultReportFormat is the output format of the subcommands that report on
+// changelogs
+const defaultReportFormat = "text"
+
+// ReportOptions represents the command line values shared by the subcommands
+// that report on changelogs, i.e. lint and semver. They check either local
+// files or the repos of a repository YAML file.
+type ReportOptions struct {
+	APIToken           string
+	APIURL             string
+	CloneDir           string
+	Files              []string
+	Format             string
+	RawURL             string
+	RepositoryFilename string
+	ReplayFile         string
+	Timeout            time.Duration
+}
+
+// newReportFlagSet returns the flag set of a subcommand that reports on
+// changelogs, with the shared flags defined. `repositoryFileUsage` describes
+// what the subcommand does with the repository YAML file.
+func (options *ReportOptions) newReportFlagSet(command string, repositoryFileUsage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [flags] [CHANGELOG.md ...]\n", filepath.Base(os.Args[0]), command)
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&options.RepositoryFilename, "f", defaultRepositoryFilename,
+		repositoryFileUsage)
+	flags.StringVar(&options.Format, "format", defaultReportFormat,
+		"Output format. Only accepts 'text' and 'json'.")
+	flags.StringVar(&options.APIToken, "p", "",
+		"GitHub API token. This can also be passed in as the 'GITHUB_TOKEN' environment variable. The flag takes precedence.")
+	flags.StringVar(&options.APIURL, "api-url", "",
+		"Base URL of the GitHub API. This can also be passed in as the 'GITHUB_API_URL' environment variable.")
+	flags.StringVar(&options.RawURL, "raw-url", "",
+		"Base URL of raw GitHub file contents. This can also be passed in as the 'GITHUB_RAW_URL' environment variable.")
+	flags.StringVar(&options.CloneDir, "clones-dir", "",
+		"Read the releases and CHANGELOGs of every repo from the git clones in this directory instead of using the network")
+	flags.StringVar(&options.ReplayFile, "replay", "",
+		"Replay HTTP responses from this cassette file instead of using the network")
+	flags.DurationVar(&options.Timeout, "timeout", http.DefaultTimeout,
+		"Time limit for each HTTP request (e.g. '30s', '2m')")
+
+	return flags
+}
+
+// parseReportFlags parses the arguments following a subcommand with its flag
+// set. Any arguments left are the files to report on.
+func (options *ReportOptions) parseReportFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	options.Files = flags.Args()
+
+	if options.Format != "text" && options.Format != "json" {
+		return fmt.Errorf("%s is not a valid %s format", options.Format, flags.Name())
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/github"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/repositories"
+)
+
+// SemverCommand is the name of the subcommand that checks the version bumps of
+// component releases against their changelogs
+const SemverCommand = "semver"
+
+// SemverOptions represents the command line values of the semver command
+type SemverOptions struct {
+	ReportOptions
+	Strict  bool
+	Version string
+}
+
+// RunSemver checks that component releases are versioned in line with their
+// changes and writes the violations found to `out`. The releases are the
+// relevant versions of every repo in the repository YAML file or, for local
+// CHANGELOG files, all of their versions. Violations are only an error in
+// strict mode so that the check can either report or gate CI.
+func RunSemver(ctx context.Context, options SemverOptions, out io.Writer) error {
+	var violations []changelog.SemverViolation
+
+	if len(options.Files) > 0 {
+		aliases, err := configuredSectionAliases(options.RepositoryFilename)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range options.Files {
+			contents, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			index, err := changelog.NewIndex(file, string(contents))
+			if err != nil {
+				return fmt.Errorf("%s: %v", file, err)
+			}
+
+			violations = append(violations, changelog.CheckSemver(file, index, nil, index.Versions(), aliases)...)
+		}
+	} else {
+		repoConfig, err := repositories.NewConfig(options.RepositoryFilename)
+		if err != nil {
+			return err
+		}
+
+		configureSources(&repoConfig, options.APIURL, options.RawURL, options.CloneDir)
+
+		httpClient, err := newHTTPClient(
+			repoConfig,
+			options.APIToken,
+			options.ReplayFile,
+			options.CloneDir,
+			options.Timeout,
+		)
+		if err != nil {
+			return err
+		}
+
+		repoViolations, err := checkReposSemver(ctx, repoConfig, httpClient, options.Version)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, repoViolations...)
+	}
+
+	err := writeSemverViolations(out, options.Format, violations)
+	if err != nil {
+		return err
+	}
+
+	if options.Strict && len(violations) > 0 {
+		return fmt.Errorf("found %d semver violation(s)", len(violations))
+	}
+
+	return nil
+}
+
+// checkReposSemver checks the relevant versions of every repo of a config,
+// matching sections through the config's section aliases
+func checkReposSemver(
+	ctx context.Context,
+	repoConfig repositories.Config,
+	httpClient http.IClient,
+	suiteVersion string,
+) ([]changelog.SemverViolation, error) {
+	aliases := changelog.NewSectionAliases(repoConfig.Section.SectionAliases)
+
+	var violations []changelog.SemverViolation
+	for _, category := range repoConfig.Section.Categories {
+		for _, repo := range category.Repos {
+			log.OutLogger.Printf("- Checking repo: %s", repo.Name)
+
+			repoViolations, err := github.CheckComponentSemver(ctx, httpClient, repo, suiteVersion, aliases)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", repo.Name, err)
+			}
+
+			violations = append(violations, repoViolations...)
+		}
+	}
+
+	return violations, nil
+}
+
+// writeSemverViolations writes violations one per line, or as a JSON array
+func writeSemverViolations(out io.Writer, format string, violations []changelog.SemverViolation) error {
+	switch format {
+	case "json":
+		if violations == nil {
+			violations = []changelog.SemverViolation{}
+		}
+
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(violations)
+	case "text":
+		for _, violation := range violations {
+			_, err := fmt.Fprintln(out, violation)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s is not a valid semver format", format)
+	}
+}
+
+// HandleInput parses the command line values of the semver command, i.e. the
+// arguments following `semver`, and stores them within a semver options struct
+func (options *SemverOptions) HandleInput(args []string) error {
+	flags := options.newReportFlagSet(SemverCommand,
+		"Repository YAML file whose repos are checked if no files are given, and whose section aliases are used")
+	flags.BoolVar(&options.Strict, "strict", false,
+		"Exit with an error if any violation is found, e.g. to gate CI")
+	flags.StringVar(&options.Version, "v", defaultVersionString,
+		"Version of the suite release, whose release branches are checked if they exist")
+
+	return options.parseReportFlags(flags, args)
+}
@@ -1,15 +1,17 @@
 package github
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/provider"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/repositories"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/version"
 )
@@ -19,11 +21,15 @@ import (
 // Note that in #155 we propose moving this into its own package, since it's not
 // really relevant to github
 type SuiteComponent struct {
-	CertificationLevel   string
-	Changelogs           []*changelog.VersionChangelog
+	CertificationLevel string
+	Changelogs         []*changelog.VersionChangelog
+	// PreviousReleaseName is the version of the previous suite release, if the
+	// component was part of it
+	PreviousReleaseName  string
 	ReleaseName          string
 	ReleaseDate          string
 	Repo                 string
+	UnreleasedChangelog  *changelog.VersionChangelog
 	UnreleasedChangesURL string
 	UpgradeURL           string
 	URL                  string
@@ -37,96 +43,23 @@ type SuiteCategory struct {
 	Components   []SuiteComponent
 }
 
-// ReleaseInfo is a representation of a v3 GitHub API JSON
-// structure denoting a release. We only are interested in
-// a small subsection of the field so this list is trimmed
-// from the full one that the API returns.
-type ReleaseInfo struct {
-	Description string `json:"body"`
-	Draft       bool   `json:"draft"`
-	Name        string `json:"name"`
-	TagName     string `json:"tag_name"`
-	Prerelease  bool   `json:"prerelease"`
-}
-
-// ComparisonInfo is a representation of a v3 GitHub API JSON
-// structure denoting a comparison. We only are interested in
-// a small subsection of the field so this list is trimmed
-// from the full one that the API returns.
-type ComparisonInfo struct {
-	URL     string `json:"html_url"`
-	AheadBy int    `json:"ahead_by"`
-}
-
-// e.g. https://api.github.com/repos/cyberark/secretless-broker/releases
-const releasesURLTemplate = "https://api.github.com/repos/%s/releases?per_page=100"
-
-// e.g. https://api.github.com/repos/cyberark/secretless-broker/compare/v1.5.2...HEAD
-const compareURLTemplate = "https://api.github.com/repos/%s/compare/%s...%s"
-
-// e.g. https://api.github.com/repos/cyberark/secretless-broker/branches/branchName
-const branchesURLTemplate = "https://api.github.com/repos/%s/branches/%s"
-
-var providerToEndpointPrefix = map[string]string{
-	"github": "https://raw.githubusercontent.com",
-}
-
-var providerVersionResolutionTemplate = map[string]string{
-	"github": "https://api.github.com/repos/%s/releases/latest",
-}
-
-// GetAvailableReleases fetches the JSON at the appropriate URL and returns just
-// the available releases as a string array.
-func GetAvailableReleases(client http.IClient, repoName string) ([]string, error) {
-	return getAvailableReleases(client, fmt.Sprintf(releasesURLTemplate, repoName))
-}
-
-func compareRefs(
-	client http.IClient,
+// GetAvailableReleases lists the releases of a repo and returns the names of
+// the ones that aren't prereleases and follow semver.
+func GetAvailableReleases(
+	ctx context.Context,
+	source provider.Provider,
 	repoName string,
-	fromRef string,
-	toRef string,
-) (*ComparisonInfo, error) {
-	return comparisonFromURL(
-		client,
-		fmt.Sprintf(compareURLTemplate, repoName, fromRef, toRef),
-	)
-}
-
-func comparisonFromURL(
-	client http.IClient,
-	url string,
-) (*ComparisonInfo, error) {
-	contents, err := client.Get(url)
-	if err != nil {
-		return nil, err
-	}
-
-	comparison := &ComparisonInfo{}
-	err = json.Unmarshal(contents, comparison)
-	if err != nil {
-		return nil, err
-	}
-
-	return comparison, nil
-}
-
-func getAvailableReleases(
-	client http.IClient,
-	releasesURL string,
 ) ([]string, error) {
-	contents, err := client.Get(releasesURL)
+	releases, err := source.ListReleases(ctx, repoName)
 	if err != nil {
 		return nil, err
 	}
 
-	var releases []ReleaseInfo
-	err = json.Unmarshal(contents, &releases)
-	if err != nil {
-		return nil, err
-	}
+	return releaseVersions(ctx, releases), nil
+}
 
-	// Convert ReleaseInfo array to an array of just the version strings
+// releaseVersions converts a list of releases to just the version strings
+func releaseVersions(ctx context.Context, releases []provider.Release) []string {
 	releaseVersions := make([]string, 0)
 	for _, release := range releases {
 		// Exclude prereleases
@@ -139,89 +72,197 @@ func getAvailableReleases(
 
 		if err != nil {
 			// Skip adding versions that don't follow semver
-			log.OutLogger.Printf("Skipping version %s", versionStr)
+			log.Out(ctx).Printf("Skipping version %s", versionStr)
 			continue
 		}
 
 		releaseVersions = append(releaseVersions, release.Name)
 	}
 
-	log.OutLogger.Printf("  Available versions: [%s]", strings.Join(releaseVersions, ", "))
+	log.Out(ctx).Printf("  Available versions: [%s]", strings.Join(releaseVersions, ", "))
 
-	return releaseVersions, nil
+	return releaseVersions
 }
 
-// FetchChangelog retrieves an existing changelog from a given provider and repository
-func fetchChangelog(
-	client http.IClient,
-	provider string,
-	repo string,
-	version string,
-) (string, error) {
+// DefaultConcurrency is the number of repos that are collected at the same
+// time when no other limit is specified
+const DefaultConcurrency = 4
 
-	// `https://raw.githubusercontent.com/cyberark/secretless-broker/master/CHANGELOG.md`
-	changelogURL := fmt.Sprintf("%s/%s/%s/CHANGELOG.md", providerToEndpointPrefix[provider], repo, version)
-	changelogBytes, err := client.Get(changelogURL)
-	if err != nil {
-		return "", err
-	}
+// ComponentError is the error returned when data for a single component could
+// not be collected
+type ComponentError struct {
+	Repo string
+	Err  error
+}
 
-	return string(changelogBytes), nil
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Repo, e.Err)
 }
 
-// CheckForBranch checks whether a branch with a specific name exists in the repo
-func checkForBranch(
-	client http.IClient,
-	provider string,
-	repo string,
-	branchName string,
-) (bool, error) {
+// Unwrap returns the underlying error of the component
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
 
-	branchURL := fmt.Sprintf(branchesURLTemplate, repo, url.QueryEscape(branchName))
-	_, err := client.Get(branchURL)
-	if err != nil {
-		if strings.Contains(err.Error(), "Branch not found") {
-			return false, nil
-		}
+// CollectionErrors aggregates the errors of all components that failed during
+// a single CollectSuiteCategories run, in suite order
+type CollectionErrors []*ComponentError
 
-		return false, err
+func (errs CollectionErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
 	}
 
-	return true, nil
+	return fmt.Sprintf(
+		"failed to collect %d component(s):\n  %s",
+		len(errs),
+		strings.Join(messages, "\n  "),
+	)
+}
+
+// componentJob identifies a repo by its position within the config
+type componentJob struct {
+	categoryIndex int
+	repoIndex     int
+	repo          repositories.Repository
+	// flushLog receives the function that logs the lines of the component
+	// once it's done
+	flushLog chan func()
 }
 
 // CollectSuiteCategories retrieves components for all categories specified
-// within a config
-func CollectSuiteCategories(repoConfig repositories.Config, httpClient http.IClient, suiteVersion string) (
+// within a config. Up to `concurrency` repos are processed at the same time
+// but the returned categories and components always follow the config order.
+// If any components fail, all of their errors are returned as CollectionErrors.
+// Cancelling the context aborts all in-flight requests and returns its error.
+func CollectSuiteCategories(
+	ctx context.Context,
+	repoConfig repositories.Config,
+	httpClient http.IClient,
+	suiteVersion string,
+	concurrency int,
+) (
 	[]SuiteCategory,
 	error,
 ) {
-	var suiteCategories []SuiteCategory
-	for _, category := range repoConfig.Section.Categories {
-		log.OutLogger.Printf("Processing category: %s", category.Name)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Results are written to pre-sized slots so that the output order does not
+	// depend on the order in which the workers finish
+	categories := repoConfig.Section.Categories
+	components := make([][]SuiteComponent, len(categories))
+	componentErrors := make([][]error, len(categories))
+	componentLogs := make([][]chan func(), len(categories))
+	for categoryIndex, category := range categories {
+		components[categoryIndex] = make([]SuiteComponent, len(category.Repos))
+		componentErrors[categoryIndex] = make([]error, len(category.Repos))
+		componentLogs[categoryIndex] = make([]chan func(), len(category.Repos))
+		for repoIndex := range category.Repos {
+			componentLogs[categoryIndex][repoIndex] = make(chan func(), 1)
+		}
+	}
 
-		var components []SuiteComponent
+	jobs := make(chan componentJob)
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			for job := range jobs {
+				// Drain the remaining jobs without doing any work once cancelled
+				if ctx.Err() != nil {
+					job.flushLog <- func() {}
+					continue
+				}
+
+				// Each component's lines are held back until the collector
+				// logs them so that concurrent ones don't interleave
+				componentCtx, flushLog := log.WithBuffer(ctx)
+				log.Out(componentCtx).Printf("- Processing repo: %s", job.repo.Name)
+
+				component, err := componentFromRepo(componentCtx, httpClient, job.repo, suiteVersion)
+				job.flushLog <- flushLog
+				if err != nil {
+					componentErrors[job.categoryIndex][job.repoIndex] = err
+					continue
+				}
+
+				// No new version between previous and current suite - discard change notes
+				if job.repo.Version == job.repo.AfterVersion {
+					component.Changelogs = nil
+				}
+
+				components[job.categoryIndex][job.repoIndex] = component
+			}
+		}()
+	}
 
-		for _, repo := range category.Repos {
-			log.OutLogger.Printf("- Processing repo: %s", repo.Name)
+	// The category headers and the lines of each component are logged in the
+	// config order, whichever order the workers finish in
+	loggingDone := make(chan struct{})
+	go func() {
+		defer close(loggingDone)
 
-			component, err := componentFromRepo(httpClient, repo, suiteVersion)
-			if err != nil {
-				return nil, err
+		for categoryIndex, category := range categories {
+			log.OutLogger.Printf("Processing category: %s", category.Name)
+
+			for _, flushLog := range componentLogs[categoryIndex] {
+				(<-flushLog)()
+			}
+		}
+	}()
+
+	for categoryIndex, category := range categories {
+		for repoIndex, repo := range category.Repos {
+			if repo.ReleaseBodies == "" {
+				repo.ReleaseBodies = repoConfig.Section.ReleaseBodies
+			}
+
+			jobs <- componentJob{
+				categoryIndex: categoryIndex,
+				repoIndex:     repoIndex,
+				repo:          repo,
+				flushLog:      componentLogs[categoryIndex][repoIndex],
 			}
+		}
+	}
+	close(jobs)
+	waitGroup.Wait()
+	<-loggingDone
+
+	// A cancelled run fails every in-flight component the same way so there's
+	// no point in listing them all
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-			// No new version between previous and current suite - discard change notes
-			if repo.Version == repo.AfterVersion {
-				component.Changelogs = nil
+	var errs CollectionErrors
+	for categoryIndex, category := range categories {
+		for repoIndex, repo := range category.Repos {
+			if err := componentErrors[categoryIndex][repoIndex]; err != nil {
+				errs = append(errs, &ComponentError{Repo: repo.Name, Err: err})
 			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
 
-			components = append(components, component)
+	var suiteCategories []SuiteCategory
+	for categoryIndex, category := range categories {
+		var categoryComponents []SuiteComponent
+		if len(category.Repos) > 0 {
+			categoryComponents = components[categoryIndex]
 		}
 
 		suiteCategories = append(suiteCategories,
 			SuiteCategory{
 				CategoryName: category.Name,
-				Components:   components,
+				Components:   categoryComponents,
 			})
 	}
 
@@ -229,6 +270,7 @@ func CollectSuiteCategories(repoConfig repositories.Config, httpClient http.ICli
 }
 
 func componentFromRepo(
+	ctx context.Context,
 	httpClient http.IClient,
 	repo repositories.Repository,
 	suiteVersion string,
@@ -241,15 +283,51 @@ func componentFromRepo(
 		UpgradeURL:         repo.UpgradeURL,
 	}
 
+	switch repo.ReleaseBodies {
+	case "", repositories.ReleaseBodiesIgnore, repositories.ReleaseBodiesFallback, repositories.ReleaseBodiesPrefer:
+	default:
+		return component, fmt.Errorf(
+			"%q is not a valid release_bodies value (expected %s, %s or %s)",
+			repo.ReleaseBodies,
+			repositories.ReleaseBodiesIgnore,
+			repositories.ReleaseBodiesFallback,
+			repositories.ReleaseBodiesPrefer,
+		)
+	}
+
+	switch repo.ChangelogSource {
+	case "", repositories.ChangelogSourceFile, repositories.ChangelogSourceCommits:
+	default:
+		return component, fmt.Errorf(
+			"%q is not a valid changelog_source value (expected %s or %s)",
+			repo.ChangelogSource,
+			repositories.ChangelogSourceFile,
+			repositories.ChangelogSourceCommits,
+		)
+	}
+
 	var changelogs []*changelog.VersionChangelog
 
 	// Repo version is the linked component release version
 	component.ReleaseName = repo.Version
+	component.PreviousReleaseName = repo.AfterVersion
+
+	// Repos are hosted on GitHub unless suite.yml says otherwise
+	source, err := provider.New(repo.Provider, httpClient, provider.Options{
+		APIURL:   repo.APIURL,
+		RawURL:   repo.RawURL,
+		BaseURL:  repo.BaseURL,
+		CloneDir: repo.CloneDir,
+	})
+	if err != nil {
+		return component, err
+	}
 
-	availableVersions, err := GetAvailableReleases(httpClient, repo.Name)
+	releases, err := source.ListReleases(ctx, repo.Name)
 	if err != nil {
 		return component, err
 	}
+	availableVersions := releaseVersions(ctx, releases)
 
 	highestVersion, err := version.HighestVersion(availableVersions)
 	if err != nil {
@@ -257,7 +335,7 @@ func componentFromRepo(
 	}
 
 	// Get a comparison between the highest version and HEAD
-	comparison, err := compareRefs(httpClient, repo.Name, highestVersion, "HEAD")
+	comparison, err := source.CompareRefs(ctx, repo.Name, highestVersion, "HEAD")
 	if err != nil {
 		return component, err
 	}
@@ -276,67 +354,59 @@ func componentFromRepo(
 		return component, err
 	}
 
-	log.OutLogger.Printf("  Relevant versions: [%s]", strings.Join(relevantVersions, ", "))
-
-	// Check if there is a "releases/{suiteVersion}" branch
-	// If it exists, use that; if not, use master.
-	branch := "master"
-	hasReleaseBranch, err := checkForBranch(
-		httpClient,
-		"github_api",
-		repo.Name,
-		fmt.Sprintf("release/%s", suiteVersion),
-	)
-	if err != nil {
-		return component, err
-	}
-
-	if hasReleaseBranch {
-		branch = fmt.Sprintf("release/%s", suiteVersion)
-		log.OutLogger.Printf("  Using release branch %s...", branch)
-	}
+	log.Out(ctx).Printf("  Relevant versions: [%s]", strings.Join(relevantVersions, ", "))
 
-	// Check if the repo has a "main" branch; if so, and there is no matching release
-	// branch for this version, use "main" as the default instead
-	if !hasReleaseBranch {
-		hasMainBranch, err := checkForBranch(
-			httpClient,
-			"github_api",
+	var changelogIndex, unreleasedIndex changelog.Index
+	if repo.ChangelogSource == repositories.ChangelogSourceCommits {
+		changelogIndex, err = commitChangelogIndex(
+			ctx,
+			source,
 			repo.Name,
-			"main",
+			availableVersions,
+			relevantVersions,
+			highestVersion,
+			comparison.AheadBy > 0,
 		)
-
 		if err != nil {
 			return component, err
 		}
-
-		if hasMainBranch {
-			branch = "main"
-
-			log.OutLogger.Print("  Using main branch...")
+		unreleasedIndex = changelogIndex
+	} else {
+		changelogIndex, unreleasedIndex, err = fetchChangelogIndexes(ctx, source, repo.Name, suiteVersion)
+		if err != nil {
+			return component, err
 		}
 	}
 
-	// TODO: This should be somehow transformed from repo url
-	completeChangelog, err := fetchChangelog(httpClient, "github", repo.Name, branch)
-	if err != nil {
-		return component, err
+	if unreleased := unreleasedIndex.Unreleased(); unreleased != nil && len(unreleased.Sections) > 0 {
+		component.UnreleasedChangelog = unreleased
 	}
 
 	// XXX: This still doesn't address releases and how we include that data in yet.
 	for _, relevantVersion := range relevantVersions {
-		log.OutLogger.Printf("  Extracting changelog data from %s...", relevantVersion)
-		versionChangelog, err := extractVersionChangelog(
-			repo.Name,
-			relevantVersion,
-			completeChangelog,
-		)
-		if err != nil {
-			return component, err
+		log.Out(ctx).Printf("  Extracting changelog data from %s...", relevantVersion)
+
+		var versionChangelog *changelog.VersionChangelog
+		if repo.ReleaseBodies == repositories.ReleaseBodiesPrefer {
+			versionChangelog, err = releaseBodyChangelog(ctx, repo.Name, releases, relevantVersion)
+			if err != nil {
+				return component, err
+			}
+		}
+
+		if versionChangelog == nil {
+			versionChangelog = changelogIndex.Get(relevantVersion)
+		}
+
+		if versionChangelog == nil && repo.ReleaseBodies == repositories.ReleaseBodiesFallback {
+			versionChangelog, err = releaseBodyChangelog(ctx, repo.Name, releases, relevantVersion)
+			if err != nil {
+				return component, err
+			}
 		}
 
 		if versionChangelog == nil {
-			log.ErrLogger.Printf(
+			log.Err(ctx).Printf(
 				"  CHANGELOG not found for %s@%s",
 				repo.Name,
 				relevantVersion,
@@ -344,6 +414,10 @@ func componentFromRepo(
 			continue
 		}
 
+		if versionChangelog.ParsedDate.IsZero() {
+			useReleaseDate(ctx, versionChangelog, releases, relevantVersion)
+		}
+
 		// If this changelog is for the suite release pinned version, save the release
 		// date. Since we don't know if there will be `v` prefixes, we compare the
 		// strings without them.
@@ -360,21 +434,254 @@ func componentFromRepo(
 	return component, nil
 }
 
-func extractVersionChangelog(
-	repo string,
-	version string,
-	log string,
+// FetchChangelog fetches the CHANGELOG of a repo from its default branch
+func FetchChangelog(
+	ctx context.Context,
+	httpClient http.IClient,
+	repo repositories.Repository,
+) (string, error) {
+	source, err := provider.New(repo.Provider, httpClient, provider.Options{
+		APIURL:   repo.APIURL,
+		RawURL:   repo.RawURL,
+		BaseURL:  repo.BaseURL,
+		CloneDir: repo.CloneDir,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	branch, err := defaultBranch(ctx, source, repo.Name)
+	if err != nil {
+		return "", err
+	}
+
+	completeChangelog, err := source.FetchFile(ctx, repo.Name, branch, "CHANGELOG.md")
+	if err != nil {
+		return "", err
+	}
+
+	return string(completeChangelog), nil
+}
+
+// releaseBodyChangelog parses the description of a release as its changelog.
+// It returns nil if there is no such release or its description has no entries.
+func releaseBodyChangelog(
+	ctx context.Context,
+	repoName string,
+	releases []provider.Release,
+	releaseName string,
 ) (*changelog.VersionChangelog, error) {
-	versionChangelogs, err := changelog.Parse(repo, log)
+	for _, release := range releases {
+		if release.Name != releaseName {
+			continue
+		}
+
+		versionChangelog, err := changelog.ParseReleaseBody(repoName, releaseName, release.Description)
+		if err != nil {
+			return nil, fmt.Errorf("release %s: %v", releaseName, err)
+		}
+
+		if versionChangelog != nil {
+			log.Out(ctx).Printf("  Using the description of release %s", releaseName)
+		}
+
+		return versionChangelog, nil
+	}
+
+	return nil, nil
+}
+
+// useReleaseDate dates a version whose changelog date is missing or can't be
+// parsed by when its release was published, if the provider records that
+func useReleaseDate(
+	ctx context.Context,
+	versionChangelog *changelog.VersionChangelog,
+	releases []provider.Release,
+	releaseName string,
+) {
+	if versionChangelog.Date != "" {
+		_, err := changelog.ParseDate(versionChangelog.Date)
+		log.Err(ctx).Printf(
+			"  WARN: Release date of %s@%s: %v",
+			versionChangelog.Repo,
+			releaseName,
+			err,
+		)
+	}
+
+	for _, release := range releases {
+		if release.Name != releaseName || release.PublishedAt.IsZero() {
+			continue
+		}
+
+		publishedAt := release.PublishedAt.UTC()
+		log.Out(ctx).Printf("  Using the publication date of release %s instead", releaseName)
+		versionChangelog.ParsedDate = time.Date(publishedAt.Year(), publishedAt.Month(), publishedAt.Day(), 0, 0, 0, 0, time.UTC)
+		versionChangelog.Date = versionChangelog.ParsedDate.Format(changelog.DefaultDateFormat)
+		return
+	}
+}
+
+// defaultBranch returns "main" if the repo has such a branch and otherwise
+// "master"
+func defaultBranch(ctx context.Context, source provider.Provider, repoName string) (string, error) {
+	hasMainBranch, err := source.BranchExists(ctx, repoName, "main")
+	if err != nil {
+		return "", err
+	}
+
+	if hasMainBranch {
+		log.Out(ctx).Print("  Using main branch...")
+		return "main", nil
+	}
+
+	return "master", nil
+}
+
+// fetchChangelogIndexes fetches and indexes the CHANGELOG of a repo for the
+// released versions, from the release branch of the suite if there is one, and
+// for the unreleased changes, from the default branch
+func fetchChangelogIndexes(
+	ctx context.Context,
+	source provider.Provider,
+	repoName string,
+	suiteVersion string,
+) (changelog.Index, changelog.Index, error) {
+	// Check if there is a "releases/{suiteVersion}" branch
+	// If it exists, use that; if not, use the default branch.
+	hasReleaseBranch, err := source.BranchExists(
+		ctx,
+		repoName,
+		fmt.Sprintf("release/%s", suiteVersion),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var branch string
+	if hasReleaseBranch {
+		branch = fmt.Sprintf("release/%s", suiteVersion)
+		log.Out(ctx).Printf("  Using release branch %s...", branch)
+	} else {
+		branch, err = defaultBranch(ctx, source, repoName)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Parse the changelog once and look up each relevant version in the index
+	changelogIndex, err := fetchChangelogIndex(ctx, source, repoName, branch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Pending changes are only tracked on the default branch
+	unreleasedIndex := changelogIndex
+	if hasReleaseBranch {
+		mainBranch, err := defaultBranch(ctx, source, repoName)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		unreleasedIndex, err = fetchChangelogIndex(ctx, source, repoName, mainBranch)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return changelogIndex, unreleasedIndex, nil
+}
+
+// fetchChangelogIndex fetches and indexes the CHANGELOG of a repo at a branch
+func fetchChangelogIndex(
+	ctx context.Context,
+	source provider.Provider,
+	repoName string,
+	branch string,
+) (changelog.Index, error) {
+	completeChangelog, err := source.FetchFile(ctx, repoName, branch, "CHANGELOG.md")
 	if err != nil {
 		return nil, err
 	}
 
-	for _, versionChangelog := range versionChangelogs {
-		if strings.TrimPrefix(version, "v") == versionChangelog.Version {
-			return versionChangelog, nil
+	return changelog.NewIndex(repoName, string(completeChangelog))
+}
+
+// commitChangelogIndex synthesizes the changelogs of a repo without a
+// CHANGELOG from its Conventional Commits, see changelog.CommitChangelog. Each
+// relevant version lists the commits since the release before it, and the
+// unreleased changes those since the highest version.
+func commitChangelogIndex(
+	ctx context.Context,
+	source provider.Provider,
+	repoName string,
+	availableVersions []string,
+	relevantVersions []string,
+	highestVersion string,
+	hasUnreleasedChanges bool,
+) (changelog.Index, error) {
+	lister, ok := source.(provider.CommitLister)
+	if !ok {
+		return nil, fmt.Errorf("the %s provider can't list commits", source.Name())
+	}
+
+	index := changelog.Index{}
+	for _, relevantVersion := range relevantVersions {
+		previousVersion, err := version.PreviousVersion(availableVersions, relevantVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		if previousVersion == "" {
+			log.Out(ctx).Printf("  No release before %s to list commits from", relevantVersion)
+			continue
+		}
+
+		versionChangelog, err := commitChangelog(ctx, lister, repoName, relevantVersion, previousVersion, relevantVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		if versionChangelog != nil {
+			index[versionChangelog.Version] = versionChangelog
+		}
+	}
+
+	if hasUnreleasedChanges {
+		unreleased, err := commitChangelog(ctx, lister, repoName, changelog.UnreleasedVersion, highestVersion, "HEAD")
+		if err != nil {
+			return nil, err
+		}
+
+		if unreleased != nil {
+			unreleased.Unreleased = true
+			index[changelog.UnreleasedVersion] = unreleased
 		}
 	}
 
-	return nil, nil
+	return index, nil
+}
+
+// commitChangelog synthesizes the changelog of a version from the commits
+// between two refs
+func commitChangelog(
+	ctx context.Context,
+	lister provider.CommitLister,
+	repoName string,
+	versionName string,
+	fromRef string,
+	toRef string,
+) (*changelog.VersionChangelog, error) {
+	log.Out(ctx).Printf("  Listing commits from %s to %s...", fromRef, toRef)
+	commits, err := lister.ListCommits(ctx, repoName, fromRef, toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	changelogCommits := make([]changelog.Commit, 0, len(commits))
+	for _, commit := range commits {
+		changelogCommits = append(changelogCommits, changelog.Commit(commit))
+	}
+
+	return changelog.CommitChangelog(repoName, versionName, changelogCommits), nil
 }
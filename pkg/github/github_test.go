@@ -1,147 +1,71 @@
 package github
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	stdlibHttp "net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
 	pkgHttp "github.com/cyberark/conjur-oss-suite-release/pkg/http"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/provider"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/repositories"
 )
 
-type MockClient struct {
-	*stdlibHttp.Client
-	AuthToken string
-}
-
-// NewClient creates a Client with an initialized parent stdlibHttp.Client
-// object
-func NewMockClient() *MockClient {
-	return &MockClient{
-		&stdlibHttp.Client{},
-		"",
+// newCassetteClient returns a client that serves the hand-written GitHub
+// responses in testdata/cassettes/github.yml without any network access
+func newCassetteClient(t *testing.T) *pkgHttp.Client {
+	cassette, err := pkgHttp.LoadCassette("testdata/cassettes/github.yml")
+	if err != nil {
+		t.Fatal(err)
 	}
-}
 
-func (client *MockClient) Get(url string) ([]byte, error) {
-	httpClient := generateHTTPClientWithFileSupportTransport()
-
-	if strings.Contains(url, "compare") {
-		return httpClient.Get("file://./testdata/compare_v3.json")
-	} else if strings.Contains(url, "CHANGELOG") {
-		if strings.Contains(url, "real_release_branch") {
-			// We are running a test where the repo has a release branch
-			return httpClient.Get("file://./testdata/release_branch_changelog.md")
-		} else if strings.Contains(url, "repo_with_main_branch") {
-			// We are running a test where the repo has a main branch
-			return httpClient.Get("file://./testdata/main_branch_changelog.md")
-		}
-		return httpClient.Get("file://./testdata/simple_changelog.md")
-	} else if strings.Contains(url, "branches") {
-		if strings.Contains(url, "real_release_branch") {
-			// We are running a test where the repo has a release branch
-			return httpClient.Get("file://./testdata/branch_v3.json")
-		} else if strings.Contains(url, "repo_with_main_branch/branches/main") {
-			// We are running a test where the repo has a release branch
-			return httpClient.Get("file://./testdata/main_v3.json")
-		}
-		return nil, fmt.Errorf("Branch not found")
-	}
-
-	// Return local data only
-	return httpClient.Get("file://./testdata/releases_v3.json")
+	return pkgHttp.NewReplayingClient(cassette)
 }
 
-func TestReleaseParsing(t *testing.T) {
-	releaseJSON, err := ioutil.ReadFile("testdata/release_v3.json")
-	if !assert.NoError(t, err) {
-		return
-	}
-
-	var releaseInfo = ReleaseInfo{}
-	err = json.Unmarshal(releaseJSON, &releaseInfo)
-	if !assert.NoError(t, err) {
-		return
+// entryTexts returns just the text of changelog entries
+func entryTexts(entries []changelog.Entry) []string {
+	var texts []string
+	for _, entry := range entries {
+		texts = append(texts, entry.Text)
 	}
 
-	description := releaseInfo.Description
-	assert.Regexp(t, regexp.MustCompile("^# Change log"), description)
-	assert.Regexp(t, regexp.MustCompile("\\(#1062\\)$"), description)
-
-	assert.Equal(t, releaseInfo.TagName, "v1.4.2")
-	assert.Equal(t, releaseInfo.Draft, false)
-	assert.Equal(t, releaseInfo.Name, "v1.4.2")
+	return texts
 }
 
-func TestReleasesParsing(t *testing.T) {
-	releaseJSON, err := ioutil.ReadFile("testdata/releases_v3.json")
-	if !assert.NoError(t, err) {
-		return
-	}
-
-	var releases = []ReleaseInfo{}
-	err = json.Unmarshal(releaseJSON, &releases)
-	if !assert.NoError(t, err) {
-		return
-	}
-
-	assert.Equal(t, len(releases), 4)
-
-	expectedReleases := []ReleaseInfo{
-		ReleaseInfo{
-			TagName: "v0.1.1",
-			Name:    "v0.1.1",
-			Draft:   false,
-		},
-		ReleaseInfo{
-			TagName: "v0.0.5",
-			Name:    "v0.0.5",
-			Draft:   false,
-		},
-		ReleaseInfo{
-			TagName: "v0.0.4",
-			Name:    "v0.0.4",
-			Draft:   false,
-		},
-		ReleaseInfo{
-			TagName: "v0.0.3",
-			Name:    "v0.0.3",
-			Draft:   false,
-		},
-	}
-
-	for index, actualRelase := range releases {
-		assert.Equal(t, actualRelase.TagName, expectedReleases[index].TagName)
-		assert.Equal(t, actualRelase.Name, expectedReleases[index].Name)
-		assert.Equal(t, actualRelase.Draft, expectedReleases[index].Draft)
-	}
+// staticProvider is a provider.Provider that only knows a fixed list of
+// releases
+type staticProvider struct {
+	provider.Provider
+	releases []provider.Release
+	err      error
 }
 
-func Test_comparisonFromURL(t *testing.T) {
-	expectedComparison := &ComparisonInfo{
-		URL:     "https://github.com/octocat/Hello-World/compare/master...topic",
-		AheadBy: 1,
-	}
-
-	httpClient := generateHTTPClientWithFileSupportTransport()
+func (source *staticProvider) ListReleases(ctx context.Context, repo string) ([]provider.Release, error) {
+	return source.releases, source.err
+}
 
-	actualComparison, err := comparisonFromURL(
-		httpClient,
-		"file://./testdata/compare_v3.json",
-	)
-	if !assert.NoError(t, err) {
-		return
+// newReleasesFileServer serves the contents of a testdata file for every request
+func newReleasesFileServer(t *testing.T, filename string) *httptest.Server {
+	contents, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	assert.Equal(t, expectedComparison, actualComparison)
+	return httptest.NewServer(stdlibHttp.HandlerFunc(func(rw stdlibHttp.ResponseWriter, req *stdlibHttp.Request) {
+		rw.Write(contents)
+	}))
 }
 
 func TestGetAvailableReleases(t *testing.T) {
@@ -151,12 +75,16 @@ func TestGetAvailableReleases(t *testing.T) {
 		"v0.0.3",
 	}
 
-	httpClient := generateHTTPClientWithFileSupportTransport()
+	source := &staticProvider{
+		releases: []provider.Release{
+			{Name: "v0.1.1", TagName: "v0.1.1"},
+			{Name: "v0.0.5", TagName: "v0.0.5"},
+			{Name: "v0.0.4", TagName: "v0.0.4", Prerelease: true},
+			{Name: "v0.0.3", TagName: "v0.0.3"},
+		},
+	}
 
-	actualReleases, err := getAvailableReleases(
-		httpClient,
-		"file://./testdata/releases_v3.json",
-	)
+	actualReleases, err := GetAvailableReleases(context.Background(), source, "cyberark/conjur-api-python3")
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -165,44 +93,16 @@ func TestGetAvailableReleases(t *testing.T) {
 }
 
 func TestGetAvailableReleasesFetchingProblem(t *testing.T) {
-	httpClient := &pkgHttp.Client{
-		&stdlibHttp.Client{},
-		"",
+	source := &staticProvider{
+		err: fmt.Errorf("code 404: some url: not found"),
 	}
 
-	_, err := getAvailableReleases(
-		httpClient,
-		"doesnotexist",
-	)
-	if !assert.Error(t, err) {
-		return
-	}
-
-	assert.EqualError(
-		t,
-		err,
-		"Get \"doesnotexist\": unsupported protocol scheme \"\"",
-	)
-}
-
-func TestGetAvailableReleasesUnmarshalingProblem(t *testing.T) {
-	httpClient := generateHTTPClientWithFileSupportTransport()
-
-	// release_v3.json (vs releases_v3.json) should fail unmarshaling since it's
-	// not an array
-	_, err := getAvailableReleases(
-		httpClient,
-		"file://./testdata/release_v3.json",
-	)
+	_, err := GetAvailableReleases(context.Background(), source, "cyberark/conjur-api-python3")
 	if !assert.Error(t, err) {
 		return
 	}
 
-	assert.EqualError(
-		t,
-		err,
-		"json: cannot unmarshal object into Go value of type []github.ReleaseInfo",
-	)
+	assert.EqualError(t, err, "code 404: some url: not found")
 }
 
 func TestGetAvailableReleasesBadSemver(t *testing.T) {
@@ -217,13 +117,14 @@ func TestGetAvailableReleasesBadSemver(t *testing.T) {
 		"v1.0.0-rc1",
 	}
 
-	httpClient := generateHTTPClientWithFileSupportTransport()
-
 	// bad_semver_releases_v3.json should skip versions with bad semver
-	actualReleases, err := getAvailableReleases(
-		httpClient,
-		"file://./testdata/bad_semver_releases_v3.json",
-	)
+	server := newReleasesFileServer(t, "bad_semver_releases_v3.json")
+	defer server.Close()
+
+	source := provider.NewGitHub(pkgHttp.NewClient())
+	source.APIURL = server.URL
+
+	actualReleases, err := GetAvailableReleases(context.Background(), source, "conjurinc/container-dap")
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -232,7 +133,7 @@ func TestGetAvailableReleasesBadSemver(t *testing.T) {
 }
 
 func TestCollectSuiteCategories(t *testing.T) {
-	mockClient := NewMockClient()
+	cassetteClient := newCassetteClient(t)
 
 	testCases := []struct {
 		description             string
@@ -288,7 +189,7 @@ func TestCollectSuiteCategories(t *testing.T) {
 				return
 			}
 
-			actualSuiteCategories, err := CollectSuiteCategories(repoConfig, mockClient, tc.releaseBranch)
+			actualSuiteCategories, err := CollectSuiteCategories(context.Background(), repoConfig, cassetteClient, tc.releaseBranch, 1)
 			if !assert.NoError(t, err) {
 				return
 			}
@@ -306,18 +207,351 @@ func TestCollectSuiteCategories(t *testing.T) {
 	}
 }
 
-func generateHTTPClientWithFileSupportTransport() *pkgHttp.Client {
-	transportWithFileSupport := &stdlibHttp.Transport{}
-	transportWithFileSupport.RegisterProtocol(
-		"file",
-		stdlibHttp.NewFileTransport(stdlibHttp.Dir(".")),
-	)
+func TestCollectSuiteCategoriesConcurrently(t *testing.T) {
+	cassetteClient := newCassetteClient(t)
+
+	repoConfig, err := generateRepoConfig(t, "multi_category_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	serialSuiteCategories, err := CollectSuiteCategories(context.Background(), repoConfig, cassetteClient, "", 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, concurrency := range []int{0, 2, 4, 16} {
+		t.Run(fmt.Sprintf("concurrency of %d matches a serial run", concurrency), func(t *testing.T) {
+			actualSuiteCategories, err := CollectSuiteCategories(context.Background(), repoConfig, cassetteClient, "", concurrency)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, serialSuiteCategories, actualSuiteCategories)
+		})
+	}
+
+	// Sanity check the ordering of the serial run itself
+	if !assert.Len(t, serialSuiteCategories, 3) {
+		return
+	}
+	assert.Equal(t, "Conjur SDK", serialSuiteCategories[0].CategoryName)
+	assert.Equal(t, "cyberark/conjur-api-python3", serialSuiteCategories[0].Components[0].Repo)
+	assert.Equal(t, "cyberark/repo_with_main_branch", serialSuiteCategories[0].Components[1].Repo)
+	assert.Nil(t, serialSuiteCategories[1].Components)
+	assert.Equal(t, "cyberark/conjur-api-go", serialSuiteCategories[2].Components[0].Repo)
+	assert.Len(t, serialSuiteCategories[2].Components[0].Changelogs, 1)
+	assert.Equal(t, "cyberark/conjur-api-java", serialSuiteCategories[2].Components[1].Repo)
+	assert.Nil(t, serialSuiteCategories[2].Components[1].Changelogs)
+}
+
+func TestCollectSuiteCategoriesLogsComponentsTogether(t *testing.T) {
+	cassetteClient := newCassetteClient(t)
+
+	repoConfig, err := generateRepoConfig(t, "multi_category_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	output := &bytes.Buffer{}
+	log.OutLogger.SetOutput(output)
+	defer log.OutLogger.SetOutput(os.Stdout)
+
+	_, err = CollectSuiteCategories(context.Background(), repoConfig, cassetteClient, "", 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Every request has to be logged under the repo it's for, and every repo
+	// under its category in the config order
+	var expectedRepos, repos []string
+	for _, category := range repoConfig.Section.Categories {
+		expectedRepos = append(expectedRepos, category.Name)
+		for _, repo := range category.Repos {
+			expectedRepos = append(expectedRepos, category.Name+" "+repo.Name)
+		}
+	}
+
+	category := ""
+	repo := ""
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		switch {
+		case strings.Contains(line, "Processing category: "):
+			category = line[strings.Index(line, ": ")+2:]
+			repos = append(repos, category)
+		case strings.Contains(line, "- Processing repo: "):
+			repo = line[strings.Index(line, ": ")+2:]
+			repos = append(repos, category+" "+repo)
+		case strings.Contains(line, "Fetching "):
+			assert.Contains(t, line, repo+"/")
+		}
+	}
+	assert.Equal(t, expectedRepos, repos)
+}
+
+func TestCollectSuiteCategoriesAggregatesErrors(t *testing.T) {
+	cassetteClient := newCassetteClient(t)
+
+	repoConfig, err := generateRepoConfig(t, "unavailable_versions_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = CollectSuiteCategories(context.Background(), repoConfig, cassetteClient, "", 4)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	collectionErrors, ok := err.(CollectionErrors)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	if !assert.Len(t, collectionErrors, 2) {
+		return
+	}
+	assert.Equal(t, "cyberark/conjur-api-python3", collectionErrors[0].Repo)
+	assert.Contains(t, collectionErrors[0].Error(), "v9.9.9 is not in available versions")
+	assert.Equal(t, "cyberark/conjur-api-java", collectionErrors[1].Repo)
+	assert.Contains(t, collectionErrors[1].Error(), "v8.8.8 is not in available versions")
+
+	assert.Contains(t, err.Error(), "failed to collect 2 component(s)")
+}
+
+func TestCollectSuiteCategoriesCancelled(t *testing.T) {
+	cassetteClient := newCassetteClient(t)
+
+	repoConfig, err := generateRepoConfig(t, "multi_category_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = CollectSuiteCategories(ctx, repoConfig, cassetteClient, "", 4)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestCollectSuiteCategoriesFromGitLab(t *testing.T) {
+	cassetteClient := newCassetteClient(t)
+
+	repoConfig, err := generateRepoConfig(t, "gitlab_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	suiteCategories, err := CollectSuiteCategories(context.Background(), repoConfig, cassetteClient, "", 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	component := suiteCategories[0].Components[0]
+	assert.Equal(t, "conjur-mirrors/conjur-api-ruby", component.Repo)
+	assert.Equal(t, "2020-02-18", component.ReleaseDate)
+	assert.Equal(t, "https://gitlab.com/conjur-mirrors/conjur-api-ruby/-/compare/v5.3.1...HEAD", component.UnreleasedChangesURL)
+	if assert.Len(t, component.Changelogs, 1) {
+		assert.Equal(t, []string{"Fixed policy loading with an empty policy body"}, entryTexts(component.Changelogs[0].Entries("Fixed")))
+	}
+}
+
+func TestCollectSuiteCategoriesFromGitHubEnterprise(t *testing.T) {
+	cassetteClient := newCassetteClient(t)
+
+	repoConfig, err := generateRepoConfig(t, "ghe_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
 
-	return &pkgHttp.Client{
-		Client: &stdlibHttp.Client{
-			Transport: transportWithFileSupport,
+	suiteCategories, err := CollectSuiteCategories(context.Background(), repoConfig, cassetteClient, "", 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	component := suiteCategories[0].Components[0]
+	assert.Equal(t, "2019-03-04", component.ReleaseDate)
+	assert.Equal(t, "", component.UnreleasedChangesURL)
+	if assert.Len(t, component.Changelogs, 1) {
+		assert.Equal(t, []string{"Converted to Golang 1.12"}, entryTexts(component.Changelogs[0].Entries("Added")))
+	}
+}
+
+func TestCollectSuiteCategoriesUnknownProvider(t *testing.T) {
+	repoConfig, err := generateRepoConfig(t, "gitlab_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	repoConfig.Section.Categories[0].Repos[0].Provider = "sourceforge"
+
+	_, err = CollectSuiteCategories(context.Background(), repoConfig, newCassetteClient(t), "", 1)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	assert.Contains(t, err.Error(), "unknown provider 'sourceforge'")
+}
+
+// widgetChangelogs are the CHANGELOGs of each commit of the test widget repo
+var widgetChangelogs = []string{
+	"# Changelog\n\n## [1.0.0] - 2020-01-01\n\n### Added\n- First release\n",
+	"# Changelog\n\n## [1.1.0] - 2020-02-01\n\n### Fixed\n- A bug\n\n## [1.0.0] - 2020-01-01\n\n### Added\n- First release\n",
+	"# Changelog\n\n## [Unreleased]\n\n### Added\n- A pending feature\n\n## [1.1.0] - 2020-02-01\n\n### Fixed\n- A bug\n\n## [1.0.0] - 2020-01-01\n\n### Added\n- First release\n",
+}
+
+// newWidgetClone creates a local clone of the widget repo: v1.0.0 and v1.1.0
+// are tagged and master is one commit ahead of v1.1.0
+func newWidgetClone(t *testing.T) (string, func()) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	clonesDir, err := ioutil.TempDir("", "widget_clones")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repoDir := filepath.Join(clonesDir, "cyberark", "widget")
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runGit := func(args ...string) {
+		command := exec.Command("git", append([]string{"-C", repoDir}, args...)...)
+		command.Env = append(
+			os.Environ(),
+			"GIT_AUTHOR_NAME=Test",
+			"GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test",
+			"GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_NOSYSTEM=1",
+		)
+		if output, err := command.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+
+	runGit("init", "--quiet")
+	runGit("checkout", "--quiet", "-b", "master")
+	for index, contents := range widgetChangelogs {
+		err := ioutil.WriteFile(filepath.Join(repoDir, "CHANGELOG.md"), []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		runGit("add", "CHANGELOG.md")
+		runGit("commit", "--quiet", "-m", "Update CHANGELOG")
+		if index < 2 {
+			runGit("tag", fmt.Sprintf("v1.%d.0", index))
+		}
+	}
+
+	return clonesDir, func() { os.RemoveAll(clonesDir) }
+}
+
+// widgetCassette holds the GitHub API responses matching newWidgetClone
+func widgetCassette() *pkgHttp.Cassette {
+	notFound := `{"message":"Branch not found"}`
+
+	return &pkgHttp.Cassette{
+		Interactions: []*pkgHttp.Interaction{
+			{
+				URL:        "https://api.github.com/repos/cyberark/widget/releases?per_page=100",
+				StatusCode: 200,
+				Body:       `[{"name": "v1.1.0", "tag_name": "v1.1.0"}, {"name": "v1.0.0", "tag_name": "v1.0.0"}]`,
+			},
+			{
+				URL:        "https://api.github.com/repos/cyberark/widget/compare/v1.1.0...HEAD",
+				StatusCode: 200,
+				Body:       `{"html_url": "https://github.com/cyberark/widget/compare/v1.1.0...HEAD", "ahead_by": 1}`,
+			},
+			{
+				URL:        "https://api.github.com/repos/cyberark/widget/branches/release%2F",
+				StatusCode: 404,
+				Body:       notFound,
+			},
+			{
+				URL:        "https://api.github.com/repos/cyberark/widget/branches/main",
+				StatusCode: 404,
+				Body:       notFound,
+			},
+			{
+				URL:        "https://raw.githubusercontent.com/cyberark/widget/master/CHANGELOG.md",
+				StatusCode: 200,
+				Body:       widgetChangelogs[len(widgetChangelogs)-1],
+			},
 		},
-		AuthToken: "",
+	}
+}
+
+func TestCollectSuiteCategoriesFromLocalClonesMatchesGitHub(t *testing.T) {
+	clonesDir, cleanup := newWidgetClone(t)
+	defer cleanup()
+
+	githubConfig, err := generateRepoConfig(t, "widget_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	githubCategories, err := CollectSuiteCategories(
+		context.Background(),
+		githubConfig,
+		pkgHttp.NewReplayingClient(widgetCassette()),
+		"",
+		1,
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	localConfig, err := generateRepoConfig(t, "widget_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	localConfig.UseLocalClones(clonesDir)
+
+	// No HTTP client at all, so any network access would blow up
+	localCategories, err := CollectSuiteCategories(context.Background(), localConfig, nil, "", 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, githubCategories, localCategories)
+
+	component := localCategories[0].Components[0]
+	assert.Equal(t, "2020-02-01", component.ReleaseDate)
+	assert.Equal(t, "https://github.com/cyberark/widget/compare/v1.1.0...HEAD", component.UnreleasedChangesURL)
+	assert.Len(t, component.Changelogs, 1)
+	if assert.NotNil(t, component.UnreleasedChangelog) {
+		assert.Equal(t, []string{"A pending feature"}, entryTexts(component.UnreleasedChangelog.Entries("Added")))
+	}
+}
+
+func TestCollectSuiteCategoriesUnreleasedFromDefaultBranch(t *testing.T) {
+	clonesDir, cleanup := newWidgetClone(t)
+	defer cleanup()
+
+	// The release branch predates the pending changes on master
+	repoDir := filepath.Join(clonesDir, "cyberark", "widget")
+	output, err := exec.Command("git", "-C", repoDir, "branch", "release/1.1", "v1.1.0").CombinedOutput()
+	if !assert.NoError(t, err, string(output)) {
+		return
+	}
+
+	repoConfig, err := generateRepoConfig(t, "widget_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	repoConfig.UseLocalClones(clonesDir)
+
+	suiteCategories, err := CollectSuiteCategories(context.Background(), repoConfig, nil, "1.1", 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	component := suiteCategories[0].Components[0]
+	assert.Len(t, component.Changelogs, 1)
+	if assert.NotNil(t, component.UnreleasedChangelog) {
+		assert.Equal(t, []string{"A pending feature"}, entryTexts(component.UnreleasedChangelog.Entries("Added")))
 	}
 }
 
@@ -351,3 +585,205 @@ func generateRepoConfig(t *testing.T,
 
 	return previousConfig, nil
 }
+
+func TestUseReleaseDate(t *testing.T) {
+	releases := []provider.Release{
+		{Name: "v1.1.0", PublishedAt: time.Date(2020, time.March, 4, 23, 30, 0, 0, time.FixedZone("EST", -5*60*60))},
+		{Name: "v1.0.0"},
+	}
+
+	// The publication date is converted to UTC before the time is dropped
+	versionChangelog := &changelog.VersionChangelog{Repo: "cyberark/widget", Version: "1.1.0", Date: "03/04/20"}
+	useReleaseDate(context.Background(), versionChangelog, releases, "v1.1.0")
+	assert.Equal(t, "2020-03-05", versionChangelog.Date)
+	assert.Equal(t, time.Date(2020, time.March, 5, 0, 0, 0, 0, time.UTC), versionChangelog.ParsedDate)
+
+	// Releases without a publication date leave the changelog alone
+	versionChangelog = &changelog.VersionChangelog{Repo: "cyberark/widget", Version: "1.0.0"}
+	useReleaseDate(context.Background(), versionChangelog, releases, "v1.0.0")
+	assert.Empty(t, versionChangelog.Date)
+	assert.True(t, versionChangelog.ParsedDate.IsZero())
+}
+
+func TestCollectSuiteCategoriesReleaseBodies(t *testing.T) {
+	releases := `[
+		{"name": "v1.1.0", "tag_name": "v1.1.0", "published_at": "2020-02-03T10:00:00Z", "body": "## Bug Fixes\r\n\r\n* A bug, as described by the release\r\n"},
+		{"name": "v1.0.0", "tag_name": "v1.0.0", "body": ""}
+	]`
+
+	testCases := []struct {
+		description     string
+		sectionDefault  string
+		releaseBodies   string
+		changelog       string
+		expectedEntries []string
+		fromReleaseBody bool
+		expectedError   string
+	}{
+		{
+			description: "ignored by default",
+			changelog:   widgetChangelogs[0],
+		},
+		{
+			description:     "fallback for a missing version",
+			releaseBodies:   repositories.ReleaseBodiesFallback,
+			changelog:       widgetChangelogs[0],
+			expectedEntries: []string{"A bug, as described by the release"},
+			fromReleaseBody: true,
+		},
+		{
+			description:     "fallback from the section",
+			sectionDefault:  repositories.ReleaseBodiesFallback,
+			changelog:       widgetChangelogs[0],
+			expectedEntries: []string{"A bug, as described by the release"},
+			fromReleaseBody: true,
+		},
+		{
+			description:     "fallback for a listed version",
+			releaseBodies:   repositories.ReleaseBodiesFallback,
+			changelog:       widgetChangelogs[1],
+			expectedEntries: []string{"A bug"},
+		},
+		{
+			description:     "preferred",
+			sectionDefault:  repositories.ReleaseBodiesIgnore,
+			releaseBodies:   repositories.ReleaseBodiesPrefer,
+			changelog:       widgetChangelogs[1],
+			expectedEntries: []string{"A bug, as described by the release"},
+			fromReleaseBody: true,
+		},
+		{
+			description:   "invalid",
+			releaseBodies: "always",
+			changelog:     widgetChangelogs[1],
+			expectedError: `"always" is not a valid release_bodies value`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cassette := widgetCassette()
+			cassette.Interactions[0].Body = releases
+			cassette.Interactions[4].Body = tc.changelog
+
+			repoConfig, err := generateRepoConfig(t, "widget_suite.yml", "")
+			if !assert.NoError(t, err) {
+				return
+			}
+			repoConfig.Section.ReleaseBodies = tc.sectionDefault
+			repoConfig.Section.Categories[0].Repos[0].ReleaseBodies = tc.releaseBodies
+
+			suiteCategories, err := CollectSuiteCategories(
+				context.Background(),
+				repoConfig,
+				pkgHttp.NewReplayingClient(cassette),
+				"",
+				1,
+			)
+			if tc.expectedError != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.expectedError)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			changelogs := suiteCategories[0].Components[0].Changelogs
+			if tc.expectedEntries == nil {
+				assert.Empty(t, changelogs)
+				return
+			}
+
+			if !assert.Len(t, changelogs, 1) || !assert.Len(t, changelogs[0].Sections, 1) {
+				return
+			}
+			assert.Equal(t, tc.expectedEntries, entryTexts(changelogs[0].Sections[0].Entries))
+			assert.Equal(t, tc.fromReleaseBody, changelogs[0].FromReleaseBody)
+			assert.Equal(t, "1.1.0", changelogs[0].Version)
+
+			// Release descriptions are dated by their publication
+			expectedDate := "2020-02-01"
+			if tc.fromReleaseBody {
+				expectedDate = "2020-02-03"
+			}
+			assert.Equal(t, expectedDate, suiteCategories[0].Components[0].ReleaseDate)
+		})
+	}
+}
+
+func TestCollectSuiteCategoriesFromCommits(t *testing.T) {
+	cassette := widgetCassette()
+	cassette.Interactions[1].Body = `{
+		"html_url": "https://github.com/cyberark/widget/compare/v1.1.0...HEAD",
+		"ahead_by": 1,
+		"commits": [
+			{"sha": "3333333333333333333333333333333333333333", "html_url": "https://github.com/cyberark/widget/commit/3333333", "commit": {"message": "feat: A pending feature"}}
+		]
+	}`
+	cassette.Interactions = append(cassette.Interactions, &pkgHttp.Interaction{
+		URL:        "https://api.github.com/repos/cyberark/widget/compare/v1.0.0...v1.1.0",
+		StatusCode: 200,
+		Body: `{
+			"html_url": "https://github.com/cyberark/widget/compare/v1.0.0...v1.1.0",
+			"ahead_by": 3,
+			"commits": [
+				{"sha": "1111111111111111111111111111111111111111", "html_url": "https://github.com/cyberark/widget/commit/1111111", "commit": {"message": "fix(api): A bug\n\nBREAKING CHANGE: Errors are now JSON"}},
+				{"sha": "2222222222222222222222222222222222222222", "html_url": "https://github.com/cyberark/widget/commit/2222222", "commit": {"message": "chore: Bump the linter"}},
+				{"sha": "4444444444444444444444444444444444444444", "html_url": "https://github.com/cyberark/widget/commit/4444444", "commit": {"message": "Merge pull request #2 from cyberark/fix"}}
+			]
+		}`,
+	})
+	// Repos without a CHANGELOG don't need one
+	cassette.Interactions[4].StatusCode = 404
+
+	repoConfig, err := generateRepoConfig(t, "widget_suite.yml", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	repoConfig.Section.Categories[0].Repos[0].ChangelogSource = repositories.ChangelogSourceCommits
+
+	suiteCategories, err := CollectSuiteCategories(
+		context.Background(),
+		repoConfig,
+		pkgHttp.NewReplayingClient(cassette),
+		"",
+		1,
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	component := suiteCategories[0].Components[0]
+	if assert.Len(t, component.Changelogs, 1) {
+		fixed := component.Changelogs[0].Entries("Fixed")
+		if assert.Len(t, fixed, 1) {
+			assert.Equal(t, "**api:** A bug ([1111111](https://github.com/cyberark/widget/commit/1111111))", fixed[0].Text)
+			assert.True(t, fixed[0].Breaking)
+		}
+		assert.Len(t, component.Changelogs[0].Sections, 1)
+	}
+
+	if assert.NotNil(t, component.UnreleasedChangelog) {
+		assert.True(t, component.UnreleasedChangelog.Unreleased)
+		assert.Equal(
+			t,
+			[]string{"A pending feature ([3333333](https://github.com/cyberark/widget/commit/3333333))"},
+			entryTexts(component.UnreleasedChangelog.Entries("Added")),
+		)
+	}
+}
+
+func TestCommitChangelogIndexUnsupportedProvider(t *testing.T) {
+	_, err := commitChangelogIndex(
+		context.Background(),
+		provider.NewGitLab(nil),
+		"cyberark/widget",
+		[]string{"v1.1.0", "v1.0.0"},
+		[]string{"v1.1.0"},
+		"v1.1.0",
+		false,
+	)
+	assert.EqualError(t, err, "the gitlab provider can't list commits")
+}
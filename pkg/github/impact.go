@@ -0,0 +1,86 @@
+package github
+
+import (
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
+)
+
+// Upgrade risks of a component, from least to most risky
+const (
+	UpgradeRiskNone   = "none"
+	UpgradeRiskLow    = "low"
+	UpgradeRiskMedium = "medium"
+	UpgradeRiskHigh   = "high"
+)
+
+// UpgradeImpact summarizes what may break when a component is upgraded from
+// its version in the previous suite release
+type UpgradeImpact struct {
+	Risk string
+	// MajorVersionBump is set if the major version changed since the previous
+	// suite release
+	MajorVersionBump bool
+	// Breaking are the entries marked as breaking changes, e.g. `BREAKING: ...`
+	Breaking []changelog.UnifiedEntry
+	// Removed and Deprecated are the other entries of those sections
+	Removed    []changelog.UnifiedEntry
+	Deprecated []changelog.UnifiedEntry
+}
+
+// UpgradeImpact scans the changelogs of the component for signs that upgrading
+// it needs care. The risk is high for a major version bump or breaking changes,
+// medium for removals and low for deprecations.
+func (component SuiteComponent) UpgradeImpact() UpgradeImpact {
+	impact := UpgradeImpact{
+		MajorVersionBump: isMajorVersionBump(component.PreviousReleaseName, component.ReleaseName),
+	}
+
+	for _, versionChangelog := range component.Changelogs {
+		for _, section := range versionChangelog.Sections {
+			for _, entry := range section.Entries {
+				unifiedEntry := changelog.UnifiedEntry{Entry: entry, Version: versionChangelog.Version}
+
+				switch {
+				case entry.Breaking:
+					impact.Breaking = append(impact.Breaking, unifiedEntry)
+				case strings.EqualFold(section.Name, "Removed"):
+					impact.Removed = append(impact.Removed, unifiedEntry)
+				case strings.EqualFold(section.Name, "Deprecated"):
+					impact.Deprecated = append(impact.Deprecated, unifiedEntry)
+				}
+			}
+		}
+	}
+
+	switch {
+	case impact.MajorVersionBump || len(impact.Breaking) > 0:
+		impact.Risk = UpgradeRiskHigh
+	case len(impact.Removed) > 0:
+		impact.Risk = UpgradeRiskMedium
+	case len(impact.Deprecated) > 0:
+		impact.Risk = UpgradeRiskLow
+	default:
+		impact.Risk = UpgradeRiskNone
+	}
+
+	return impact
+}
+
+// isMajorVersionBump checks whether the major version of a release is higher
+// than that of a previous one. Releases that aren't semantic versions never
+// are.
+func isMajorVersionBump(previousReleaseName string, releaseName string) bool {
+	previousVersion, err := semver.NewVersion(strings.TrimPrefix(previousReleaseName, "v"))
+	if err != nil {
+		return false
+	}
+
+	version, err := semver.NewVersion(strings.TrimPrefix(releaseName, "v"))
+	if err != nil {
+		return false
+	}
+
+	return version.Major > previousVersion.Major
+}
@@ -0,0 +1,83 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
+)
+
+func impactChangelog(version string, sections ...changelog.Section) *changelog.VersionChangelog {
+	return &changelog.VersionChangelog{Repo: "cyberark/widget", Version: version, Sections: sections}
+}
+
+func impactSection(name string, texts ...string) changelog.Section {
+	section := changelog.Section{Name: name}
+	for _, text := range texts {
+		section.Entries = append(section.Entries, changelog.NewEntry("cyberark/widget", text))
+	}
+
+	return section
+}
+
+func TestUpgradeImpact(t *testing.T) {
+	component := SuiteComponent{
+		PreviousReleaseName: "v1.0.0",
+		ReleaseName:         "v1.2.0",
+		Changelogs: []*changelog.VersionChangelog{
+			impactChangelog("1.2.0",
+				impactSection("Changed", "BREAKING: Hosts need the `authn` permission", "Faster startup"),
+				impactSection("Removed", "Removed the v4 API"),
+			),
+			impactChangelog("1.1.0",
+				impactSection("Deprecated", "The v4 API is deprecated"),
+				impactSection("Removed", "Removed `--legacy` (breaking change)"),
+			),
+		},
+	}
+
+	impact := component.UpgradeImpact()
+	assert.Equal(t, UpgradeRiskHigh, impact.Risk)
+	assert.False(t, impact.MajorVersionBump)
+
+	// Breaking entries are only listed as such
+	if assert.Len(t, impact.Breaking, 2) {
+		assert.Equal(t, "1.2.0", impact.Breaking[0].Version)
+		assert.Equal(t, "1.1.0", impact.Breaking[1].Version)
+		assert.Equal(t, "Removed `--legacy` (breaking change)", impact.Breaking[1].Text)
+	}
+	if assert.Len(t, impact.Removed, 1) {
+		assert.Equal(t, "Removed the v4 API", impact.Removed[0].Text)
+	}
+	assert.Len(t, impact.Deprecated, 1)
+}
+
+func TestUpgradeImpactRisk(t *testing.T) {
+	testCases := []struct {
+		description         string
+		previousReleaseName string
+		releaseName         string
+		sections            []changelog.Section
+		expectedRisk        string
+	}{
+		{"major version bump", "v1.4.0", "v2.0.1", nil, UpgradeRiskHigh},
+		{"no previous version", "", "v2.0.0", nil, UpgradeRiskNone},
+		{"not semver", "latest", "v2.0.0", nil, UpgradeRiskNone},
+		{"removals", "v1.0.0", "v1.1.0", []changelog.Section{impactSection("removed", "Dropped X")}, UpgradeRiskMedium},
+		{"deprecations", "v1.0.0", "v1.1.0", []changelog.Section{impactSection("Deprecated", "X is deprecated")}, UpgradeRiskLow},
+		{"additions", "v1.0.0", "v1.1.0", []changelog.Section{impactSection("Added", "Y")}, UpgradeRiskNone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			component := SuiteComponent{
+				PreviousReleaseName: tc.previousReleaseName,
+				ReleaseName:         tc.releaseName,
+				Changelogs:          []*changelog.VersionChangelog{impactChangelog("1.1.0", tc.sections...)},
+			}
+
+			assert.Equal(t, tc.expectedRisk, component.UpgradeImpact().Risk)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/provider"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/repositories"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/version"
+)
+
+// CheckComponentSemver checks the version bumps of the relevant versions of a
+// repo against its CHANGELOG, see changelog.CheckSemver. The CHANGELOG is read
+// from the same branch as when the suite release notes are generated. Repos
+// whose changelogs come from their commits have nothing to check.
+func CheckComponentSemver(
+	ctx context.Context,
+	httpClient http.IClient,
+	repo repositories.Repository,
+	suiteVersion string,
+	aliases changelog.SectionAliases,
+) ([]changelog.SemverViolation, error) {
+	if repo.ChangelogSource == repositories.ChangelogSourceCommits {
+		log.Out(ctx).Printf("  Skipping %s since it has no CHANGELOG", repo.Name)
+		return nil, nil
+	}
+
+	source, err := provider.New(repo.Provider, httpClient, provider.Options{
+		APIURL:   repo.APIURL,
+		RawURL:   repo.RawURL,
+		BaseURL:  repo.BaseURL,
+		CloneDir: repo.CloneDir,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	availableVersions, err := GetAvailableReleases(ctx, source, repo.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	relevantVersions, err := version.GetRelevantVersions(
+		availableVersions,
+		repo.AfterVersion,
+		repo.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Out(ctx).Printf("  Relevant versions: [%s]", strings.Join(relevantVersions, ", "))
+
+	changelogIndex, _, err := fetchChangelogIndexes(ctx, source, repo.Name, suiteVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return changelog.CheckSemver(repo.Name, changelogIndex, availableVersions, relevantVersions, aliases), nil
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	stdlibHttp "net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+)
+
+// cacheEntry is the on-disk representation of a cached response
+type cacheEntry struct {
+	URL    string            `json:"url"`
+	ETag   string            `json:"etag"`
+	Header stdlibHttp.Header `json:"header"`
+	Body   []byte            `json:"body"`
+}
+
+// CachingClient is an IClient decorator that stores responses on disk and
+// revalidates them with conditional `If-None-Match` requests. GitHub doesn't
+// count `304 Not Modified` answers against the API rate limit, so repeated
+// runs are nearly free.
+//
+// Only responses that carry an `ETag` are cached. If the wrapped client can't
+// send request headers (i.e. it isn't an IHeaderClient), every request is
+// passed through unchanged.
+type CachingClient struct {
+	Client IClient
+	Dir    string
+}
+
+// NewCachingClient wraps a client with an on-disk cache stored in `dir`
+func NewCachingClient(client IClient, dir string) *CachingClient {
+	return &CachingClient{
+		Client: client,
+		Dir:    dir,
+	}
+}
+
+// DefaultCacheDir returns the per-user directory used for cached responses,
+// or an empty string if the platform doesn't define one
+func DefaultCacheDir() string {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(userCacheDir, "conjur-oss-suite-release")
+}
+
+// Get retrieves the content of a URL, serving it from the cache if the server
+// confirms that the cached copy is still current
+func (client *CachingClient) Get(ctx context.Context, url string) (*Response, error) {
+	headerClient, ok := client.Client.(IHeaderClient)
+	if !ok {
+		return client.Client.Get(ctx, url)
+	}
+
+	entry := client.read(url)
+
+	header := stdlibHttp.Header{}
+	if entry != nil {
+		header.Set("If-None-Match", entry.ETag)
+	}
+
+	response, err := headerClient.GetWithHeaders(ctx, url, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == stdlibHttp.StatusNotModified {
+		if entry == nil {
+			// We never asked for this so there is nothing to fall back to
+			return client.Client.Get(ctx, url)
+		}
+
+		log.Out(ctx).Printf("  Using cached %s", url)
+		return &Response{
+			StatusCode: stdlibHttp.StatusOK,
+			Header:     entry.Header,
+			Body:       entry.Body,
+		}, nil
+	}
+
+	if etag := response.Header.Get("ETag"); etag != "" {
+		err = client.write(&cacheEntry{
+			URL:    url,
+			ETag:   etag,
+			Header: response.Header,
+			Body:   response.Body,
+		})
+
+		// A failed write only costs us a refetch on the next run
+		if err != nil {
+			log.Err(ctx).Printf("  WARN: Could not cache %s: %v", url, err)
+		}
+	}
+
+	return response, nil
+}
+
+func (client *CachingClient) path(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(client.Dir, hex.EncodeToString(hash[:])+".json")
+}
+
+// read returns the cached entry for a URL, treating unreadable entries as
+// cache misses
+func (client *CachingClient) read(url string) *cacheEntry {
+	contents, err := ioutil.ReadFile(client.path(url))
+	if err != nil {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(contents, entry); err != nil || entry.URL != url || entry.ETag == "" {
+		return nil
+	}
+
+	return entry
+}
+
+// write stores an entry in the cache
+func (client *CachingClient) write(entry *cacheEntry) error {
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(client.Dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that concurrent readers never observe
+	// a partially written entry
+	tempFile, err := ioutil.TempFile(client.Dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(contents)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), client.path(entry.URL))
+}
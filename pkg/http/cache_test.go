@@ -0,0 +1,172 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newETagServer serves a fixed body with an ETag and honors If-None-Match,
+// counting full and not-modified responses
+func newETagServer(etag string) (*httptest.Server, *int, *int) {
+	fullResponses := 0
+	notModifiedResponses := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if etag != "" && req.Header.Get("If-None-Match") == etag {
+			notModifiedResponses++
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		fullResponses++
+		if etag != "" {
+			rw.Header().Set("ETag", etag)
+		}
+		rw.Header().Set("Link", `<https://example.com/items?page=2>; rel="next"`)
+		rw.Write([]byte("Page Content"))
+	}))
+
+	return server, &fullResponses, &notModifiedResponses
+}
+
+func newTempCacheDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "http_cache_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestCachingClientRevalidatesWithETag(t *testing.T) {
+	server, fullResponses, notModifiedResponses := newETagServer(`"abc123"`)
+	defer server.Close()
+
+	cacheDir := newTempCacheDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	client := NewCachingClient(NewClient(), cacheDir)
+
+	for i := 0; i < 3; i++ {
+		response, err := client.Get(context.Background(), server.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, []byte("Page Content"), response.Body)
+		assert.Equal(t, "https://example.com/items?page=2", response.NextPageURL())
+	}
+
+	assert.Equal(t, 1, *fullResponses)
+	assert.Equal(t, 2, *notModifiedResponses)
+}
+
+func TestCachingClientPersistsAcrossClients(t *testing.T) {
+	server, fullResponses, notModifiedResponses := newETagServer(`"abc123"`)
+	defer server.Close()
+
+	cacheDir := newTempCacheDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	for i := 0; i < 2; i++ {
+		client := NewCachingClient(NewClient(), cacheDir)
+		_, err := client.Get(context.Background(), server.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	assert.Equal(t, 1, *fullResponses)
+	assert.Equal(t, 1, *notModifiedResponses)
+}
+
+func TestCachingClientSkipsResponsesWithoutETag(t *testing.T) {
+	server, fullResponses, _ := newETagServer("")
+	defer server.Close()
+
+	cacheDir := newTempCacheDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	client := NewCachingClient(NewClient(), cacheDir)
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(context.Background(), server.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	assert.Equal(t, 2, *fullResponses)
+
+	files, err := ioutil.ReadDir(cacheDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, files)
+}
+
+func TestCachingClientIgnoresCorruptEntries(t *testing.T) {
+	server, fullResponses, _ := newETagServer(`"abc123"`)
+	defer server.Close()
+
+	cacheDir := newTempCacheDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	client := NewCachingClient(NewClient(), cacheDir)
+	err := ioutil.WriteFile(client.path(server.URL), []byte("{not json"), 0644)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	response, err := client.Get(context.Background(), server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []byte("Page Content"), response.Body)
+	assert.Equal(t, 1, *fullResponses)
+
+	// The corrupt entry should have been replaced by a valid one
+	assert.NotNil(t, client.read(server.URL))
+}
+
+// plainClient is an IClient that can't send request headers
+type plainClient struct {
+	requests int
+}
+
+func (client *plainClient) Get(ctx context.Context, url string) (*Response, error) {
+	client.requests++
+
+	header := http.Header{}
+	header.Set("ETag", `"abc123"`)
+	return &Response{StatusCode: http.StatusOK, Header: header, Body: []byte("Page Content")}, nil
+}
+
+func TestCachingClientPassesThroughPlainClients(t *testing.T) {
+	cacheDir := newTempCacheDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	inner := &plainClient{}
+	client := NewCachingClient(inner, cacheDir)
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(context.Background(), "https://example.com")
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	assert.Equal(t, 2, inner.requests)
+
+	files, err := ioutil.ReadDir(cacheDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, files)
+}
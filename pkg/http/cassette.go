@@ -0,0 +1,208 @@
+package http
+
+import (
+	"fmt"
+	"io/ioutil"
+	stdlibHttp "net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// recordedHeaders lists the response headers that are kept in a cassette. Other
+// headers are either volatile (dates, request IDs, rate limits) or unused and
+// would only make recordings noisy and non-reproducible.
+var recordedHeaders = []string{
+	"Content-Type",
+	"ETag",
+	"Last-Modified",
+	"Link",
+}
+
+// Interaction is a single recorded HTTP request and its response. Request
+// headers (and with them any auth tokens) are never recorded.
+type Interaction struct {
+	Method     string            `yaml:"method"`
+	URL        string            `yaml:"url"`
+	StatusCode int               `yaml:"status"`
+	Header     stdlibHttp.Header `yaml:"headers,omitempty"`
+	Body       string            `yaml:"body"`
+}
+
+func (interaction *Interaction) key() string {
+	return interactionKey(interaction.Method, interaction.URL)
+}
+
+func interactionKey(method string, url string) string {
+	return method + " " + url
+}
+
+// Cassette is a bundle of recorded HTTP interactions that can be replayed
+// without any network access
+type Cassette struct {
+	Interactions []*Interaction `yaml:"interactions"`
+}
+
+// LoadCassette reads a cassette from a YAML file
+func LoadCassette(filename string) (*Cassette, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cassette: %s", err)
+	}
+
+	cassette := &Cassette{}
+	err = yaml.Unmarshal(contents, cassette)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling cassette %s: %s", filename, err)
+	}
+
+	return cassette, nil
+}
+
+// Save writes the cassette to a YAML file
+func (cassette *Cassette) Save(filename string) error {
+	contents, err := yaml.Marshal(cassette)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, contents, 0644)
+}
+
+// Recorder is a stdlibHttp.RoundTripper that records every exchange made
+// through the wrapped transport so that it can later be saved as a Cassette
+type Recorder struct {
+	Transport stdlibHttp.RoundTripper
+
+	mutex        sync.Mutex
+	interactions map[string]*Interaction
+}
+
+// NewRecorder wraps a transport with a Recorder. A nil transport records
+// the exchanges of stdlibHttp.DefaultTransport.
+func NewRecorder(transport stdlibHttp.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = stdlibHttp.DefaultTransport
+	}
+
+	return &Recorder{
+		Transport:    transport,
+		interactions: map[string]*Interaction{},
+	}
+}
+
+// RoundTrip performs the request using the wrapped transport and records the
+// response. Repeated requests for the same URL keep the latest response.
+func (recorder *Recorder) RoundTrip(request *stdlibHttp.Request) (*stdlibHttp.Response, error) {
+	response, err := recorder.Transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Method:     request.Method,
+		URL:        request.URL.String(),
+		StatusCode: response.StatusCode,
+		Body:       string(body),
+	}
+	for _, name := range recordedHeaders {
+		if values := response.Header.Values(name); len(values) > 0 {
+			if interaction.Header == nil {
+				interaction.Header = stdlibHttp.Header{}
+			}
+			interaction.Header[name] = values
+		}
+	}
+
+	recorder.mutex.Lock()
+	recorder.interactions[interaction.key()] = interaction
+	recorder.mutex.Unlock()
+
+	// The body has been consumed so hand the caller a fresh copy
+	response.Body = ioutil.NopCloser(strings.NewReader(interaction.Body))
+	return response, nil
+}
+
+// Cassette returns everything recorded so far, sorted by URL so that
+// recordings of concurrent runs are stable
+func (recorder *Recorder) Cassette() *Cassette {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	cassette := &Cassette{}
+	for _, interaction := range recorder.interactions {
+		cassette.Interactions = append(cassette.Interactions, interaction)
+	}
+	sort.Slice(cassette.Interactions, func(i, j int) bool {
+		return cassette.Interactions[i].key() < cassette.Interactions[j].key()
+	})
+
+	return cassette
+}
+
+// Replayer is a stdlibHttp.RoundTripper that answers requests from a
+// Cassette and never touches the network
+type Replayer struct {
+	interactions map[string]*Interaction
+}
+
+// NewReplayer creates a Replayer serving the interactions of a cassette
+func NewReplayer(cassette *Cassette) *Replayer {
+	replayer := &Replayer{
+		interactions: map[string]*Interaction{},
+	}
+	for _, interaction := range cassette.Interactions {
+		if interaction.Method == "" {
+			interaction.Method = "GET"
+		}
+		replayer.interactions[interaction.key()] = interaction
+	}
+
+	return replayer
+}
+
+// RoundTrip returns the recorded response for the request, or an error if
+// the request was never recorded
+func (replayer *Replayer) RoundTrip(request *stdlibHttp.Request) (*stdlibHttp.Response, error) {
+	if err := request.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	interaction, ok := replayer.interactions[interactionKey(request.Method, request.URL.String())]
+	if !ok {
+		return nil, fmt.Errorf("no recorded response for %s %s", request.Method, request.URL)
+	}
+
+	header := stdlibHttp.Header{}
+	for name, values := range interaction.Header {
+		header[stdlibHttp.CanonicalHeaderKey(name)] = values
+	}
+
+	return &stdlibHttp.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, stdlibHttp.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(interaction.Body)),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       request,
+	}, nil
+}
+
+// NewReplayingClient creates a Client that serves every request from a
+// cassette. Retries are disabled since replayed responses never change.
+func NewReplayingClient(cassette *Cassette) *Client {
+	return &Client{
+		Client: &stdlibHttp.Client{Transport: NewReplayer(cassette)},
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("ETag", `"abc123"`)
+		rw.Header().Set("Link", `<https://example.com/items?page=2>; rel="next"`)
+		rw.Header().Set("X-Request-Id", "volatile")
+		rw.Write([]byte("Page Content"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.AuthToken = "secret-token"
+	recorder := NewRecorder(client.Transport)
+	client.Transport = recorder
+
+	response, err := client.Get(context.Background(), server.URL+"/items")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// The caller still gets the full body after it has been recorded
+	assert.Equal(t, []byte("Page Content"), response.Body)
+
+	cassetteDir, err := ioutil.TempDir("", "cassette_test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(cassetteDir)
+
+	cassetteFile := filepath.Join(cassetteDir, "cassette.yml")
+	if !assert.NoError(t, recorder.Cassette().Save(cassetteFile)) {
+		return
+	}
+
+	contents, err := ioutil.ReadFile(cassetteFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotContains(t, string(contents), "secret-token")
+	assert.NotContains(t, string(contents), "X-Request-Id")
+
+	cassette, err := LoadCassette(cassetteFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Nothing should reach the network once we're replaying
+	server.Close()
+
+	replayed, err := NewReplayingClient(cassette).Get(context.Background(), server.URL+"/items")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, http.StatusOK, replayed.StatusCode)
+	assert.Equal(t, []byte("Page Content"), replayed.Body)
+	assert.Equal(t, `"abc123"`, replayed.Header.Get("ETag"))
+	assert.Equal(t, "https://example.com/items?page=2", replayed.NextPageURL())
+}
+
+func TestCassetteReplaysErrorResponses(t *testing.T) {
+	cassette := &Cassette{
+		Interactions: []*Interaction{
+			{
+				URL:        "https://example.com/missing",
+				StatusCode: http.StatusNotFound,
+				Body:       `{"message":"Not Found"}`,
+			},
+		},
+	}
+
+	_, err := NewReplayingClient(cassette).Get(context.Background(), "https://example.com/missing")
+	assert.EqualError(t, err, `code 404: https://example.com/missing: {"message":"Not Found"}`)
+}
+
+func TestCassetteReplayFailsOnUnrecordedRequests(t *testing.T) {
+	client := NewReplayingClient(&Cassette{})
+
+	_, err := client.Get(context.Background(), "https://example.com/unknown")
+	if !assert.Error(t, err) {
+		return
+	}
+
+	assert.Contains(t, err.Error(), "no recorded response for GET https://example.com/unknown")
+}
@@ -1,48 +1,257 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	stdlibHttp "net/http"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
 )
 
 // IClient outlines the requirement for an HTTP Client
 type IClient interface {
-	// Get defines a function that retrieves the contents of a url
-	Get(url string) ([]byte, error)
+	// Get defines a function that retrieves the contents and headers of a url.
+	// Cancelling the context aborts the request, including any pending retries.
+	Get(ctx context.Context, url string) (*Response, error)
+}
+
+// IHeaderClient is an IClient that can also send extra request headers. This
+// is what allows decorators to make conditional (`If-None-Match`) requests.
+type IHeaderClient interface {
+	IClient
+
+	// GetWithHeaders retrieves a url like Get but adds the specified request
+	// headers. A `304 Not Modified` response is returned rather than an error.
+	GetWithHeaders(ctx context.Context, url string, header stdlibHttp.Header) (*Response, error)
+}
+
+// Response holds the parts of an HTTP response that callers care about
+type Response struct {
+	StatusCode int
+	Header     stdlibHttp.Header
+	Body       []byte
+}
+
+// StatusError is returned when the server answers with an unsuccessful status
+// code, after any retries have been exhausted
+type StatusError struct {
+	StatusCode int
+	URL        string
+	Body       []byte
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("code %d: %s: %s", err.StatusCode, err.URL, err.Body)
+}
+
+// IsNotFound checks whether an error (or any error it wraps) is a
+// `404 Not Found` StatusError
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == stdlibHttp.StatusNotFound
+}
+
+// linkNextRegexp matches the `rel="next"` entry of a `Link` header, e.g.
+// <https://api.github.com/repositories/1/releases?page=2>; rel="next"
+var linkNextRegexp = regexp.MustCompile(`<([^>]+)>\s*;[^,]*rel="?next"?`)
+
+// NextPageURL returns the URL of the next page of a paginated response as
+// advertised by its `Link` header, or an empty string for the last page
+func (response *Response) NextPageURL() string {
+	for _, link := range response.Header.Values("Link") {
+		match := linkNextRegexp.FindStringSubmatch(link)
+		if len(match) > 1 {
+			return match[1]
+		}
+	}
+
+	return ""
 }
 
 // Client is a wrapper around stdlibHttp client but with added storage for
-// an auth token
+// an auth token. It waits out API rate limits and retries transient server
+// errors up to MaxRetries times.
+//
+// If AuthHosts is set, the AuthToken is only sent to those hosts so that a
+// token for one service doesn't leak to another.
 type Client struct {
 	*stdlibHttp.Client
-	AuthToken string
+	AuthToken        string
+	AuthHosts        []string
+	MaxRetries       int
+	MaxRateLimitWait time.Duration
+
+	rateLimit rateLimitState
+
+	// Overridable for tests
+	sleep func(time.Duration)
+	clock func() time.Time
 }
 
+// DefaultTimeout is the time limit for a single request attempt, including
+// reading the response body
+const DefaultTimeout = 60 * time.Second
+
 // NewClient creates a Client with an initialized parent stdlibHttp.Client
 // object
 func NewClient() *Client {
 	return &Client{
-		&stdlibHttp.Client{},
-		"",
+		Client:           &stdlibHttp.Client{Timeout: DefaultTimeout},
+		MaxRetries:       DefaultMaxRetries,
+		MaxRateLimitWait: DefaultMaxRateLimitWait,
+	}
+}
+
+// RateLimit returns the API rate limit state from the most recent response
+// that reported one. The boolean is false if no response has reported it yet.
+func (client *Client) RateLimit() (RateLimit, bool) {
+	return client.rateLimit.get()
+}
+
+func (client *Client) now() time.Time {
+	if client.clock != nil {
+		return client.clock()
+	}
+
+	return time.Now()
+}
+
+// wait pauses for the specified duration, refusing to pause for longer than
+// MaxRateLimitWait. The pause ends early if the context is cancelled.
+func (client *Client) wait(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
 	}
+
+	if client.MaxRateLimitWait > 0 && duration > client.MaxRateLimitWait {
+		return fmt.Errorf(
+			"refusing to wait %s for the rate limit to reset (maximum is %s)",
+			duration.Round(time.Second),
+			client.MaxRateLimitWait,
+		)
+	}
+
+	if client.sleep != nil {
+		client.sleep(duration)
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Get retrieves the content and headers of a URL. Rate-limited (403/429) and
+// transient server error (5xx) responses are retried after waiting for the
+// duration the server asks for, or with a jittered exponential backoff.
+func (client *Client) Get(ctx context.Context, url string) (*Response, error) {
+	return client.GetWithHeaders(ctx, url, nil)
+}
+
+// GetWithHeaders is Get with additional request headers. Since it's meant for
+// conditional requests, `304 Not Modified` responses are not treated as errors.
+func (client *Client) GetWithHeaders(
+	ctx context.Context,
+	url string,
+	header stdlibHttp.Header,
+) (*Response, error) {
+	for attempt := 0; ; attempt++ {
+		// If the last response told us we're out of requests, pause until the
+		// limit resets instead of burning a request on a guaranteed failure
+		if reset := client.rateLimit.exhaustedUntil(); reset.After(client.now()) {
+			duration := reset.Sub(client.now()) + time.Second
+			// Waits are logged right away, rather than with the rest of a
+			// buffered component, so that a stalled run is explained
+			log.ErrLogger.Printf("  WARN: API rate limit exhausted, waiting %s...", duration.Round(time.Second))
+			if err := client.wait(ctx, duration); err != nil {
+				return nil, err
+			}
+		}
+
+		response, err := client.get(ctx, url, header)
+		if err != nil {
+			return nil, err
+		}
+
+		client.rateLimit.update(response.Header)
+
+		if response.StatusCode < 300 || response.StatusCode == stdlibHttp.StatusNotModified {
+			return response, nil
+		}
+
+		requestErr := &StatusError{
+			StatusCode: response.StatusCode,
+			URL:        url,
+			Body:       response.Body,
+		}
+
+		delay, retryable := client.retryDelay(response, attempt)
+		if !retryable || attempt >= client.MaxRetries {
+			return nil, requestErr
+		}
+
+		log.ErrLogger.Printf(
+			"  WARN: %v (retrying in %s, attempt %d of %d)",
+			requestErr,
+			delay.Round(time.Millisecond),
+			attempt+1,
+			client.MaxRetries,
+		)
+		if err := client.wait(ctx, delay); err != nil {
+			return nil, fmt.Errorf("%v: %w", requestErr, err)
+		}
+	}
+}
+
+// sendsTokenTo checks whether the AuthToken may be sent to a host
+func (client *Client) sendsTokenTo(host string) bool {
+	if len(client.AuthHosts) == 0 {
+		return true
+	}
+
+	for _, authHost := range client.AuthHosts {
+		if strings.EqualFold(authHost, host) {
+			return true
+		}
+	}
+
+	return false
 }
 
-// Get retrieves the content of a URL
-func (client *Client) Get(url string) ([]byte, error) {
-	request, err := stdlibHttp.NewRequest("GET", url, nil)
+// get performs a single GET request without any retry handling
+func (client *Client) get(
+	ctx context.Context,
+	url string,
+	header stdlibHttp.Header,
+) (*Response, error) {
+	request, err := stdlibHttp.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	for key, values := range header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
 	// Add API auth token if one is provided
-	if client.AuthToken != "" {
+	if client.AuthToken != "" && client.sendsTokenTo(request.URL.Hostname()) {
 		request.Header.Add("Authorization", "token "+client.AuthToken)
 	}
 
-	log.OutLogger.Printf("  Fetching %s...", url)
+	log.Out(ctx).Printf("  Fetching %s...", url)
 	response, err := client.Do(request)
 	if err != nil {
 		return nil, err
@@ -55,9 +264,9 @@ func (client *Client) Get(url string) ([]byte, error) {
 		return nil, err
 	}
 
-	if response.StatusCode >= 300 {
-		return nil, fmt.Errorf("code %d: %s: %s", response.StatusCode, url, contents)
-	}
-
-	return contents, nil
+	return &Response{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       contents,
+	}, nil
 }
@@ -1,9 +1,12 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,12 +22,12 @@ func TestHttpClientGet(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient()
-	content, err := client.Get(server.URL + testPath)
+	content, err := client.Get(context.Background(), server.URL+testPath)
 	if !assert.NoError(t, err) {
 		return
 	}
 
-	assert.Equal(t, []byte("Page Content"), content)
+	assert.Equal(t, []byte("Page Content"), content.Body)
 }
 
 func TestHttpClientGetTokenSupport(t *testing.T) {
@@ -41,17 +44,32 @@ func TestHttpClientGetTokenSupport(t *testing.T) {
 	client := NewClient()
 	client.AuthToken = githubToken
 
-	content, err := client.Get(server.URL + testPath)
+	content, err := client.Get(context.Background(), server.URL+testPath)
 	if !assert.NoError(t, err) {
 		return
 	}
 
-	assert.Equal(t, []byte("Page Content"), content)
+	assert.Equal(t, []byte("Page Content"), content.Body)
+}
+
+func TestHttpClientGetTokenOnlySentToAuthHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "", req.Header.Get("Authorization"))
+		rw.Write([]byte("Page Content"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.AuthToken = "myapikey"
+	client.AuthHosts = []string{"api.github.com"}
+
+	_, err := client.Get(context.Background(), server.URL)
+	assert.NoError(t, err)
 }
 
 func TestHttpClientGetRequestUrlProblem(t *testing.T) {
 	client := NewClient()
-	_, err := client.Get("zzz")
+	_, err := client.Get(context.Background(), "zzz")
 	if !assert.Error(t, err) {
 		return
 	}
@@ -68,10 +86,136 @@ func TestHttpClientGetRequestBadStatusCode(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient()
-	_, err := client.Get(server.URL + testPath)
+	_, err := client.Get(context.Background(), server.URL+testPath)
 	if !assert.Error(t, err) {
 		return
 	}
 
 	assert.EqualError(t, err, "code 404: "+server.URL+testPath+": ")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestHttpClientGetExposesHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Custom-Header", "custom value")
+		rw.Write([]byte("Page Content"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	response, err := client.Get(context.Background(), server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "custom value", response.Header.Get("X-Custom-Header"))
+}
+
+func TestResponseNextPageURL(t *testing.T) {
+	testCases := []struct {
+		description string
+		linkHeaders []string
+		expectedURL string
+	}{
+		{
+			description: "no link header",
+			linkHeaders: nil,
+			expectedURL: "",
+		},
+		{
+			description: "next and last pages",
+			linkHeaders: []string{
+				`<https://api.github.com/repositories/1/releases?per_page=100&page=2>; rel="next", ` +
+					`<https://api.github.com/repositories/1/releases?per_page=100&page=5>; rel="last"`,
+			},
+			expectedURL: "https://api.github.com/repositories/1/releases?per_page=100&page=2",
+		},
+		{
+			description: "next page listed after other relations",
+			linkHeaders: []string{
+				`<https://example.com/items?page=1>; rel="prev", <https://example.com/items?page=3>; rel="next"`,
+			},
+			expectedURL: "https://example.com/items?page=3",
+		},
+		{
+			description: "last page",
+			linkHeaders: []string{
+				`<https://example.com/items?page=1>; rel="first", <https://example.com/items?page=4>; rel="prev"`,
+			},
+			expectedURL: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			response := &Response{Header: http.Header{}}
+			for _, linkHeader := range tc.linkHeaders {
+				response.Header.Add("Link", linkHeader)
+			}
+
+			assert.Equal(t, tc.expectedURL, response.NextPageURL())
+		})
+	}
+}
+
+func TestHttpClientGetTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	client := NewClient()
+	client.Timeout = 50 * time.Millisecond
+
+	_, err := client.Get(context.Background(), server.URL)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	assert.Contains(t, err.Error(), "Client.Timeout exceeded")
+}
+
+func TestHttpClientGetCancellation(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	client := NewClient()
+	_, err := client.Get(ctx, server.URL)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestHttpClientCancellationInterruptsRetryWait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Retry-After", "600")
+		rw.WriteHeader(429)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	client := NewClient()
+
+	start := time.Now()
+	_, err := client.Get(ctx, server.URL)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
 }
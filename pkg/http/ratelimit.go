@@ -0,0 +1,173 @@
+package http
+
+import (
+	"fmt"
+	"math/rand"
+	stdlibHttp "net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRetries is the number of times a rate-limited or transiently
+// failing request is retried before giving up
+const DefaultMaxRetries = 5
+
+// DefaultMaxRateLimitWait is the longest the client will pause for a rate
+// limit to reset. GitHub resets its primary rate limit every hour.
+const DefaultMaxRateLimitWait = time.Hour
+
+// Backoff bounds used when the server doesn't tell us how long to wait
+const minBackoff = 1 * time.Second
+const maxBackoff = 60 * time.Second
+
+// RateLimit is the API rate limit state most recently reported by the server
+// through the `X-RateLimit-*` headers
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Used      int
+	Reset     time.Time
+}
+
+func (rateLimit RateLimit) String() string {
+	return fmt.Sprintf(
+		"%d of %d requests remaining (%d used), resets at %s",
+		rateLimit.Remaining,
+		rateLimit.Limit,
+		rateLimit.Used,
+		rateLimit.Reset.Format(time.RFC3339),
+	)
+}
+
+// rateLimitState is the thread-safe store of the last seen RateLimit
+type rateLimitState struct {
+	mutex     sync.Mutex
+	known     bool
+	rateLimit RateLimit
+}
+
+func (state *rateLimitState) get() (RateLimit, bool) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	return state.rateLimit, state.known
+}
+
+// update records the rate limit headers of a response, if it has any
+func (state *rateLimitState) update(header stdlibHttp.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	rateLimit := RateLimit{Remaining: remaining}
+	rateLimit.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	rateLimit.Used, _ = strconv.Atoi(header.Get("X-RateLimit-Used"))
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rateLimit.Reset = time.Unix(reset, 0)
+	}
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	state.known = true
+	state.rateLimit = rateLimit
+}
+
+// exhaustedUntil returns the reset time if the last seen rate limit has no
+// requests remaining, and the zero time otherwise
+func (state *rateLimitState) exhaustedUntil() time.Time {
+	rateLimit, known := state.get()
+	if !known || rateLimit.Remaining > 0 {
+		return time.Time{}
+	}
+
+	return rateLimit.Reset
+}
+
+// retryAfter parses a `Retry-After` header, which is either a number of
+// seconds or an HTTP date
+func retryAfter(header stdlibHttp.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := stdlibHttp.ParseTime(value); err == nil {
+		return date.Sub(now), true
+	}
+
+	return 0, false
+}
+
+// isRateLimited checks whether a 403/429 response was caused by a (primary or
+// secondary) rate limit rather than by missing permissions
+func isRateLimited(response *Response) bool {
+	switch response.StatusCode {
+	case stdlibHttp.StatusTooManyRequests:
+		return true
+	case stdlibHttp.StatusForbidden:
+		if response.Header.Get("X-RateLimit-Remaining") == "0" {
+			return true
+		}
+		if response.Header.Get("Retry-After") != "" {
+			return true
+		}
+
+		return strings.Contains(strings.ToLower(string(response.Body)), "rate limit")
+	}
+
+	return false
+}
+
+// isTransient checks whether a response is a server error worth retrying
+func isTransient(response *Response) bool {
+	switch response.StatusCode {
+	case stdlibHttp.StatusInternalServerError,
+		stdlibHttp.StatusBadGateway,
+		stdlibHttp.StatusServiceUnavailable,
+		stdlibHttp.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// backoff returns an exponentially growing delay for the given attempt with
+// "equal jitter" applied, so that concurrent workers don't retry in lockstep
+func backoff(attempt int) time.Duration {
+	delay := minBackoff << uint(attempt)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryDelay works out whether a failed response should be retried and how
+// long to wait before doing so
+func (client *Client) retryDelay(response *Response, attempt int) (time.Duration, bool) {
+	if !isRateLimited(response) && !isTransient(response) {
+		return 0, false
+	}
+
+	now := client.now()
+	if wait, ok := retryAfter(response.Header, now); ok {
+		return wait, true
+	}
+
+	if response.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset := client.rateLimit.exhaustedUntil(); !reset.IsZero() {
+			// Add a second of margin so we don't wake up right before the reset
+			return reset.Sub(now) + time.Second, true
+		}
+	}
+
+	return backoff(attempt), true
+}
@@ -0,0 +1,288 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+)
+
+// newTestClient returns a client whose sleeps only advance a fake clock, and
+// records every requested sleep
+func newTestClient() (*Client, *[]time.Duration) {
+	sleeps := []time.Duration{}
+	now := time.Date(2020, 2, 19, 12, 0, 0, 0, time.UTC)
+
+	client := NewClient()
+	client.clock = func() time.Time { return now }
+	client.sleep = func(duration time.Duration) {
+		sleeps = append(sleeps, duration)
+		now = now.Add(duration)
+	}
+
+	return client, &sleeps
+}
+
+// newSequenceServer returns a server that answers each request with the next
+// handler in the sequence, repeating the last one once it runs out
+func newSequenceServer(handlers ...http.HandlerFunc) (*httptest.Server, *int) {
+	requestCount := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handler := handlers[len(handlers)-1]
+		if requestCount < len(handlers) {
+			handler = handlers[requestCount]
+		}
+		requestCount++
+
+		handler(rw, req)
+	}))
+
+	return server, &requestCount
+}
+
+func okHandler(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("X-RateLimit-Limit", "5000")
+	rw.Header().Set("X-RateLimit-Remaining", "4998")
+	rw.Header().Set("X-RateLimit-Used", "2")
+	rw.Header().Set("X-RateLimit-Reset", "1582117200")
+	rw.Write([]byte("Page Content"))
+}
+
+func statusHandler(status int, headers map[string]string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		for key, value := range headers {
+			rw.Header().Set(key, value)
+		}
+		rw.WriteHeader(status)
+	}
+}
+
+func TestHttpClientRetriesAfterRetryAfterHeader(t *testing.T) {
+	server, requestCount := newSequenceServer(
+		statusHandler(429, map[string]string{"Retry-After": "7"}),
+		okHandler,
+	)
+	defer server.Close()
+
+	client, sleeps := newTestClient()
+	response, err := client.Get(context.Background(), server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []byte("Page Content"), response.Body)
+	assert.Equal(t, 2, *requestCount)
+	assert.Equal(t, []time.Duration{7 * time.Second}, *sleeps)
+}
+
+func TestHttpClientWaitsForPrimaryRateLimitReset(t *testing.T) {
+	client, sleeps := newTestClient()
+	reset := client.now().Add(30 * time.Second)
+
+	server, requestCount := newSequenceServer(
+		statusHandler(403, map[string]string{
+			"X-RateLimit-Limit":     "60",
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Used":      "60",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+		}),
+		okHandler,
+	)
+	defer server.Close()
+
+	_, err := client.Get(context.Background(), server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 2, *requestCount)
+	assert.Equal(t, []time.Duration{31 * time.Second}, *sleeps)
+}
+
+func TestHttpClientPausesBeforeRequestWhenRateLimitIsExhausted(t *testing.T) {
+	client, sleeps := newTestClient()
+	reset := client.now().Add(10 * time.Second)
+
+	server, requestCount := newSequenceServer(
+		statusHandler(200, map[string]string{
+			"X-RateLimit-Limit":     "60",
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+		}),
+		okHandler,
+	)
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(context.Background(), server.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	assert.Equal(t, 2, *requestCount)
+	assert.Equal(t, []time.Duration{11 * time.Second}, *sleeps)
+}
+
+func TestHttpClientLogsWaitsRightAway(t *testing.T) {
+	server, _ := newSequenceServer(
+		statusHandler(429, map[string]string{"Retry-After": "7"}),
+		okHandler,
+	)
+	defer server.Close()
+
+	output := &bytes.Buffer{}
+	log.ErrLogger.SetOutput(output)
+	defer log.ErrLogger.SetOutput(os.Stderr)
+
+	// The warning mustn't be held back with the rest of a component's lines
+	ctx, flushLog := log.WithBuffer(context.Background())
+	defer flushLog()
+
+	client, _ := newTestClient()
+	_, err := client.Get(ctx, server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Contains(t, output.String(), "retrying in 7s, attempt 1 of 5")
+}
+
+func TestHttpClientBacksOffOnSecondaryRateLimit(t *testing.T) {
+	server, requestCount := newSequenceServer(
+		func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(403)
+			rw.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+		},
+		okHandler,
+	)
+	defer server.Close()
+
+	client, sleeps := newTestClient()
+	_, err := client.Get(context.Background(), server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 2, *requestCount)
+	if assert.Len(t, *sleeps, 1) {
+		assert.GreaterOrEqual(t, int64((*sleeps)[0]), int64(minBackoff/2))
+		assert.LessOrEqual(t, int64((*sleeps)[0]), int64(minBackoff))
+	}
+}
+
+func TestHttpClientRetriesTransientServerErrors(t *testing.T) {
+	server, requestCount := newSequenceServer(
+		statusHandler(502, nil),
+		statusHandler(503, nil),
+		okHandler,
+	)
+	defer server.Close()
+
+	client, sleeps := newTestClient()
+	response, err := client.Get(context.Background(), server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []byte("Page Content"), response.Body)
+	assert.Equal(t, 3, *requestCount)
+	if assert.Len(t, *sleeps, 2) {
+		for attempt, sleep := range *sleeps {
+			maxDelay := minBackoff << uint(attempt)
+			assert.GreaterOrEqual(t, int64(sleep), int64(maxDelay/2))
+			assert.LessOrEqual(t, int64(sleep), int64(maxDelay))
+		}
+	}
+}
+
+func TestHttpClientGivesUpAfterMaxRetries(t *testing.T) {
+	server, requestCount := newSequenceServer(statusHandler(500, nil))
+	defer server.Close()
+
+	client, sleeps := newTestClient()
+	client.MaxRetries = 2
+
+	_, err := client.Get(context.Background(), server.URL)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	assert.EqualError(t, err, "code 500: "+server.URL+": ")
+	assert.Equal(t, 3, *requestCount)
+	assert.Len(t, *sleeps, 2)
+}
+
+func TestHttpClientDoesNotRetryClientErrors(t *testing.T) {
+	for _, status := range []int{403, 404} {
+		t.Run(fmt.Sprintf("status %d", status), func(t *testing.T) {
+			server, requestCount := newSequenceServer(statusHandler(status, nil))
+			defer server.Close()
+
+			client, sleeps := newTestClient()
+			_, err := client.Get(context.Background(), server.URL)
+			if !assert.Error(t, err) {
+				return
+			}
+
+			assert.Equal(t, 1, *requestCount)
+			assert.Empty(t, *sleeps)
+		})
+	}
+}
+
+func TestHttpClientRefusesToWaitTooLong(t *testing.T) {
+	server, requestCount := newSequenceServer(
+		statusHandler(429, map[string]string{"Retry-After": "7200"}),
+	)
+	defer server.Close()
+
+	client, sleeps := newTestClient()
+	_, err := client.Get(context.Background(), server.URL)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	assert.Contains(t, err.Error(), "refusing to wait 2h0m0s for the rate limit to reset")
+	assert.Equal(t, 1, *requestCount)
+	assert.Empty(t, *sleeps)
+}
+
+func TestHttpClientRateLimit(t *testing.T) {
+	server, _ := newSequenceServer(okHandler)
+	defer server.Close()
+
+	client, _ := newTestClient()
+
+	_, known := client.RateLimit()
+	assert.False(t, known)
+
+	_, err := client.Get(context.Background(), server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rateLimit, known := client.RateLimit()
+	assert.True(t, known)
+	assert.Equal(t, RateLimit{
+		Limit:     5000,
+		Remaining: 4998,
+		Used:      2,
+		Reset:     time.Unix(1582117200, 0),
+	}, rateLimit)
+	assert.Equal(
+		t,
+		"4998 of 5000 requests remaining (2 used), resets at "+time.Unix(1582117200, 0).Format(time.RFC3339),
+		rateLimit.String(),
+	)
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// bufferKey is the context key of a buffer
+type bufferKey struct{}
+
+// flushMutex keeps the lines of buffers that are flushed at the same time
+// apart
+var flushMutex sync.Mutex
+
+// buffer holds back the lines of OutLogger and ErrLogger in the order they
+// were logged
+type buffer struct {
+	mutex  sync.Mutex
+	lines  []bufferedLine
+	out    *log.Logger
+	errors *log.Logger
+}
+
+type bufferedLine struct {
+	isError bool
+	line    []byte
+}
+
+// bufferWriter appends what its logger writes to a buffer
+type bufferWriter struct {
+	buffer  *buffer
+	isError bool
+}
+
+func (writer bufferWriter) Write(line []byte) (int, error) {
+	writer.buffer.mutex.Lock()
+	defer writer.buffer.mutex.Unlock()
+
+	writer.buffer.lines = append(writer.buffer.lines, bufferedLine{
+		isError: writer.isError,
+		line:    append([]byte(nil), line...),
+	})
+
+	return len(line), nil
+}
+
+// WithBuffer returns a context whose loggers, see Out and Err, hold back their
+// lines until the returned flush function is called. This keeps the lines of
+// work that runs concurrently, e.g. collecting a component, together.
+func WithBuffer(ctx context.Context) (context.Context, func()) {
+	logBuffer := &buffer{}
+	logBuffer.out = log.New(bufferWriter{buffer: logBuffer}, OutLogger.Prefix(), OutLogger.Flags())
+	logBuffer.errors = log.New(bufferWriter{buffer: logBuffer, isError: true}, ErrLogger.Prefix(), ErrLogger.Flags())
+
+	flush := func() {
+		flushMutex.Lock()
+		defer flushMutex.Unlock()
+
+		logBuffer.mutex.Lock()
+		defer logBuffer.mutex.Unlock()
+
+		for _, bufferedLine := range logBuffer.lines {
+			writer := OutLogger.Writer()
+			if bufferedLine.isError {
+				writer = ErrLogger.Writer()
+			}
+			writer.Write(bufferedLine.line)
+		}
+		logBuffer.lines = nil
+	}
+
+	return context.WithValue(ctx, bufferKey{}, logBuffer), flush
+}
+
+// Out returns the logger of `ctx` for progress, i.e. that of its buffer if it
+// has one and otherwise OutLogger
+func Out(ctx context.Context) *log.Logger {
+	if logBuffer, ok := ctx.Value(bufferKey{}).(*buffer); ok {
+		return logBuffer.out
+	}
+
+	return OutLogger
+}
+
+// Err returns the logger of `ctx` for warnings and errors, i.e. that of its
+// buffer if it has one and otherwise ErrLogger
+func Err(ctx context.Context) *log.Logger {
+	if logBuffer, ok := ctx.Value(bufferKey{}).(*buffer); ok {
+		return logBuffer.errors
+	}
+
+	return ErrLogger
+}
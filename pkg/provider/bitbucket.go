@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+// Default Bitbucket Cloud endpoints
+const defaultBitbucketAPIURL = "https://api.bitbucket.org/2.0"
+const defaultBitbucketURL = "https://bitbucket.org"
+
+// bitbucketPage is the envelope of every paginated Bitbucket v2 API response.
+// Unlike the other providers, the next page is linked from the body rather
+// than a `Link` header.
+type bitbucketPage struct {
+	Values []json.RawMessage `json:"values"`
+	Next   string            `json:"next"`
+}
+
+// bitbucketTag is a trimmed representation of a Bitbucket v2 API tag
+type bitbucketTag struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// Bitbucket is the Provider for Bitbucket Cloud. Bitbucket has no concept of
+// releases so every tag is treated as one.
+type Bitbucket struct {
+	Client http.IClient
+	APIURL string
+	URL    string
+}
+
+// NewBitbucket creates a Bitbucket provider for bitbucket.org
+func NewBitbucket(client http.IClient) *Bitbucket {
+	return &Bitbucket{
+		Client: client,
+		APIURL: defaultBitbucketAPIURL,
+		URL:    defaultBitbucketURL,
+	}
+}
+
+// NewBitbucketWithOptions creates a Bitbucket provider for the instance at
+// options.BaseURL. Its API is expected at `<BaseURL>/api/2.0`, which
+// bitbucket.org serves as well.
+func NewBitbucketWithOptions(client http.IClient, options Options) *Bitbucket {
+	bitbucket := NewBitbucket(client)
+	if options.BaseURL != "" {
+		bitbucket.URL = strings.TrimSuffix(options.BaseURL, "/")
+		bitbucket.APIURL = bitbucket.URL + "/api/2.0"
+	}
+
+	return bitbucket
+}
+
+// Name returns "bitbucket"
+func (bitbucket *Bitbucket) Name() string {
+	return "bitbucket"
+}
+
+func (bitbucket *Bitbucket) repoURL(repo string) string {
+	return fmt.Sprintf("%s/repositories/%s", bitbucket.APIURL, repo)
+}
+
+// getAllValues fetches every page of a listing, following the `next` links
+func (bitbucket *Bitbucket) getAllValues(ctx context.Context, firstPageURL string) ([]json.RawMessage, error) {
+	var values []json.RawMessage
+
+	// Guard against servers that link back to a page we've already seen
+	visitedURLs := map[string]bool{}
+	for pageURL := firstPageURL; pageURL != "" && !visitedURLs[pageURL]; {
+		visitedURLs[pageURL] = true
+
+		page := bitbucketPage{}
+		err := getJSON(ctx, bitbucket.Client, pageURL, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, page.Values...)
+		pageURL = page.Next
+	}
+
+	return values, nil
+}
+
+// ListReleases returns every tag of the repo, newest first
+func (bitbucket *Bitbucket) ListReleases(ctx context.Context, repo string) ([]Release, error) {
+	tagsURL := bitbucket.repoURL(repo) + "/refs/tags?pagelen=100&sort=-target.date"
+
+	values, err := bitbucket.getAllValues(ctx, tagsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make([]Release, 0, len(values))
+	for _, value := range values {
+		tag := bitbucketTag{}
+		err := json.Unmarshal(value, &tag)
+		if err != nil {
+			return nil, err
+		}
+
+		releases = append(releases, Release{
+			Description: tag.Message,
+			Name:        tag.Name,
+			TagName:     tag.Name,
+		})
+	}
+
+	return releases, nil
+}
+
+// CompareRefs counts the commits reachable from `toRef` but not from
+// `fromRef`
+func (bitbucket *Bitbucket) CompareRefs(
+	ctx context.Context,
+	repo string,
+	fromRef string,
+	toRef string,
+) (*Comparison, error) {
+	commitsURL := fmt.Sprintf(
+		"%s/commits/%s?exclude=%s&pagelen=100",
+		bitbucket.repoURL(repo),
+		url.PathEscape(toRef),
+		url.QueryEscape(fromRef),
+	)
+
+	commits, err := bitbucket.getAllValues(ctx, commitsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comparison{
+		// Bitbucket separates the refs of a comparison with a carriage return
+		URL:     fmt.Sprintf("%s/%s/branches/compare/%s%%0D%s", bitbucket.URL, repo, toRef, fromRef),
+		AheadBy: len(commits),
+	}, nil
+}
+
+// BranchExists checks whether a branch with a specific name exists in the repo
+func (bitbucket *Bitbucket) BranchExists(ctx context.Context, repo string, branch string) (bool, error) {
+	branchURL := bitbucket.repoURL(repo) + "/refs/branches/" + url.PathEscape(branch)
+
+	return branchExists(ctx, bitbucket.Client, branchURL)
+}
+
+// FetchFile retrieves a file using the source API
+func (bitbucket *Bitbucket) FetchFile(ctx context.Context, repo string, ref string, path string) ([]byte, error) {
+	fileURL := fmt.Sprintf("%s/src/%s/%s", bitbucket.repoURL(repo), url.PathEscape(ref), path)
+
+	response, err := bitbucket.Client.Get(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Body, nil
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+func newTestBitbucket(server string) *Bitbucket {
+	bitbucket := NewBitbucket(http.NewClient())
+	bitbucket.APIURL = server + "/2.0"
+	bitbucket.URL = server
+
+	return bitbucket
+}
+
+func TestBitbucketListReleases(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/2.0/repositories/mirrors/conjur/refs/tags?pagelen=100&sort=-target.date": {
+			body: `{
+				"values": [{"name": "v1.0.1", "message": "Fixes"}],
+				"next": "{{server}}/2.0/repositories/mirrors/conjur/refs/tags?page=2"
+			}`,
+		},
+		"/2.0/repositories/mirrors/conjur/refs/tags?page=2": {
+			body: `{"values": [{"name": "v1.0.0"}]}`,
+		},
+	})
+	defer server.Close()
+
+	releases, err := newTestBitbucket(server.URL).ListReleases(context.Background(), "mirrors/conjur")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Release{
+		{Name: "v1.0.1", TagName: "v1.0.1", Description: "Fixes"},
+		{Name: "v1.0.0", TagName: "v1.0.0"},
+	}, releases)
+}
+
+func TestBitbucketCompareRefs(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/2.0/repositories/mirrors/conjur/commits/HEAD?exclude=v1.0.1&pagelen=100": {
+			body: `{
+				"values": [{"hash": "abc"}, {"hash": "def"}],
+				"next": "{{server}}/2.0/repositories/mirrors/conjur/commits/HEAD?page=2"
+			}`,
+		},
+		"/2.0/repositories/mirrors/conjur/commits/HEAD?page=2": {
+			body: `{"values": [{"hash": "123"}]}`,
+		},
+	})
+	defer server.Close()
+
+	comparison, err := newTestBitbucket(server.URL).CompareRefs(context.Background(), "mirrors/conjur", "v1.0.1", "HEAD")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, &Comparison{
+		URL:     server.URL + "/mirrors/conjur/branches/compare/HEAD%0Dv1.0.1",
+		AheadBy: 3,
+	}, comparison)
+}
+
+func TestBitbucketBranchExists(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/2.0/repositories/mirrors/conjur/refs/branches/release%2F1.0": {
+			body: `{"name": "release/1.0"}`,
+		},
+	})
+	defer server.Close()
+
+	bitbucket := newTestBitbucket(server.URL)
+
+	exists, err := bitbucket.BranchExists(context.Background(), "mirrors/conjur", "release/1.0")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = bitbucket.BranchExists(context.Background(), "mirrors/conjur", "main")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestBitbucketFetchFile(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/2.0/repositories/mirrors/conjur/src/release%2F1.0/CHANGELOG.md": {
+			body: "# Changelog\n",
+		},
+	})
+	defer server.Close()
+
+	contents, err := newTestBitbucket(server.URL).FetchFile(context.Background(), "mirrors/conjur", "release/1.0", "CHANGELOG.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "# Changelog\n", string(contents))
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+const defaultGiteaURL = "https://gitea.com"
+
+// giteaRelease is a trimmed representation of a Gitea v1 API release
+type giteaRelease struct {
+	Description string    `json:"body"`
+	Draft       bool      `json:"draft"`
+	Name        string    `json:"name"`
+	TagName     string    `json:"tag_name"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// giteaComparison is a trimmed representation of a Gitea v1 API comparison
+type giteaComparison struct {
+	TotalCommits int `json:"total_commits"`
+}
+
+// Gitea is the Provider for Gitea (and Forgejo) instances
+type Gitea struct {
+	Client http.IClient
+	URL    string
+}
+
+// NewGitea creates a Gitea provider for gitea.com
+func NewGitea(client http.IClient) *Gitea {
+	return &Gitea{
+		Client: client,
+		URL:    defaultGiteaURL,
+	}
+}
+
+// NewGiteaWithOptions creates a Gitea provider for the instance at
+// options.BaseURL, e.g. `https://codeberg.org`
+func NewGiteaWithOptions(client http.IClient, options Options) *Gitea {
+	gitea := NewGitea(client)
+	if options.BaseURL != "" {
+		gitea.URL = strings.TrimSuffix(options.BaseURL, "/")
+	}
+
+	return gitea
+}
+
+// Name returns "gitea"
+func (gitea *Gitea) Name() string {
+	return "gitea"
+}
+
+func (gitea *Gitea) repoURL(repo string) string {
+	return fmt.Sprintf("%s/api/v1/repos/%s", gitea.URL, repo)
+}
+
+// ListReleases fetches every page of a repo's releases
+func (gitea *Gitea) ListReleases(ctx context.Context, repo string) ([]Release, error) {
+	// Gitea caps the page size at 50 by default
+	releasesURL := gitea.repoURL(repo) + "/releases?limit=50"
+
+	var releases []Release
+	err := getAllPages(ctx, gitea.Client, releasesURL, func(body []byte) error {
+		var pageReleases []giteaRelease
+		err := json.Unmarshal(body, &pageReleases)
+		if err != nil {
+			return err
+		}
+
+		for _, release := range pageReleases {
+			releases = append(releases, Release(release))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// CompareRefs compares two refs using the compare API (Gitea 1.18+)
+func (gitea *Gitea) CompareRefs(
+	ctx context.Context,
+	repo string,
+	fromRef string,
+	toRef string,
+) (*Comparison, error) {
+	compareURL := fmt.Sprintf("%s/compare/%s...%s", gitea.repoURL(repo), fromRef, toRef)
+
+	comparison := giteaComparison{}
+	err := getJSON(ctx, gitea.Client, compareURL, &comparison)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comparison{
+		URL:     fmt.Sprintf("%s/%s/compare/%s...%s", gitea.URL, repo, fromRef, toRef),
+		AheadBy: comparison.TotalCommits,
+	}, nil
+}
+
+// BranchExists checks whether a branch with a specific name exists in the repo
+func (gitea *Gitea) BranchExists(ctx context.Context, repo string, branch string) (bool, error) {
+	branchURL := gitea.repoURL(repo) + "/branches/" + url.PathEscape(branch)
+
+	return branchExists(ctx, gitea.Client, branchURL)
+}
+
+// FetchFile retrieves a file using the raw content API
+func (gitea *Gitea) FetchFile(ctx context.Context, repo string, ref string, path string) ([]byte, error) {
+	fileURL := fmt.Sprintf("%s/raw/%s?ref=%s", gitea.repoURL(repo), path, url.QueryEscape(ref))
+
+	response, err := gitea.Client.Get(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Body, nil
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+func newTestGitea(server string) *Gitea {
+	gitea := NewGitea(http.NewClient())
+	gitea.URL = server
+
+	return gitea
+}
+
+func TestGiteaListReleases(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/v1/repos/mirrors/conjur/releases?limit=50": {
+			link: `<{{server}}/api/v1/repos/mirrors/conjur/releases?limit=50&page=2>; rel="next"`,
+			body: `[
+				{"name": "v1.1.0-rc1", "tag_name": "v1.1.0-rc1", "body": "Soon", "prerelease": true},
+				{"name": "v1.0.1", "tag_name": "v1.0.1", "body": "Fixes", "published_at": "2020-11-05T21:42:08Z"}
+			]`,
+		},
+		"/api/v1/repos/mirrors/conjur/releases?limit=50&page=2": {
+			body: `[{"name": "v1.0.0", "tag_name": "v1.0.0", "body": "First", "draft": true}]`,
+		},
+	})
+	defer server.Close()
+
+	releases, err := newTestGitea(server.URL).ListReleases(context.Background(), "mirrors/conjur")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Release{
+		{Name: "v1.1.0-rc1", TagName: "v1.1.0-rc1", Description: "Soon", Prerelease: true},
+		{
+			Name:        "v1.0.1",
+			TagName:     "v1.0.1",
+			Description: "Fixes",
+			PublishedAt: time.Date(2020, time.November, 5, 21, 42, 8, 0, time.UTC),
+		},
+		{Name: "v1.0.0", TagName: "v1.0.0", Description: "First", Draft: true},
+	}, releases)
+}
+
+func TestGiteaCompareRefs(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/v1/repos/mirrors/conjur/compare/v1.0.1...HEAD": {
+			body: `{"total_commits": 3, "commits": [{}, {}, {}]}`,
+		},
+	})
+	defer server.Close()
+
+	comparison, err := newTestGitea(server.URL).CompareRefs(context.Background(), "mirrors/conjur", "v1.0.1", "HEAD")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, &Comparison{
+		URL:     server.URL + "/mirrors/conjur/compare/v1.0.1...HEAD",
+		AheadBy: 3,
+	}, comparison)
+}
+
+func TestGiteaBranchExists(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/v1/repos/mirrors/conjur/branches/release%2F1.0": {
+			body: `{"name": "release/1.0"}`,
+		},
+	})
+	defer server.Close()
+
+	gitea := newTestGitea(server.URL)
+
+	exists, err := gitea.BranchExists(context.Background(), "mirrors/conjur", "release/1.0")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = gitea.BranchExists(context.Background(), "mirrors/conjur", "main")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestGiteaFetchFile(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/v1/repos/mirrors/conjur/raw/CHANGELOG.md?ref=main": {
+			body: "# Changelog\n",
+		},
+	})
+	defer server.Close()
+
+	contents, err := newTestGitea(server.URL).FetchFile(context.Background(), "mirrors/conjur", "main", "CHANGELOG.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "# Changelog\n", string(contents))
+}
@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+// Default GitHub endpoints
+const defaultGitHubAPIURL = "https://api.github.com"
+const defaultGitHubRawURL = "https://raw.githubusercontent.com"
+
+// GitHubAuthHosts are the hosts that a GitHub API token should be sent to
+var GitHubAuthHosts = []string{"api.github.com", "raw.githubusercontent.com"}
+
+// githubRelease is a representation of a v3 GitHub API JSON structure
+// denoting a release. We only are interested in a small subsection of the
+// field so this list is trimmed from the full one that the API returns.
+type githubRelease struct {
+	Description string    `json:"body"`
+	Draft       bool      `json:"draft"`
+	Name        string    `json:"name"`
+	TagName     string    `json:"tag_name"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// githubComparison is a trimmed representation of a v3 GitHub API JSON
+// structure denoting a comparison
+type githubComparison struct {
+	URL     string         `json:"html_url"`
+	AheadBy int            `json:"ahead_by"`
+	Commits []githubCommit `json:"commits"`
+}
+
+// githubCommit is a trimmed representation of a v3 GitHub API JSON structure
+// denoting a commit
+type githubCommit struct {
+	SHA    string `json:"sha"`
+	URL    string `json:"html_url"`
+	Commit struct {
+		Message string `json:"message"`
+	} `json:"commit"`
+}
+
+// githubContent is a trimmed representation of a v3 GitHub API JSON
+// structure denoting the contents of a file
+type githubContent struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// GitHub is the Provider for github.com and GitHub Enterprise. If RawURL is
+// empty, files are fetched through the contents API instead.
+type GitHub struct {
+	Client http.IClient
+	APIURL string
+	RawURL string
+}
+
+// NewGitHub creates a GitHub provider using the public github.com endpoints
+func NewGitHub(client http.IClient) *GitHub {
+	return &GitHub{
+		Client: client,
+		APIURL: defaultGitHubAPIURL,
+		RawURL: defaultGitHubRawURL,
+	}
+}
+
+// NewGitHubWithOptions creates a GitHub provider with custom endpoints, e.g.
+// `https://ghe.example.com/api/v3` for GitHub Enterprise. The raw content
+// host can't be derived from a custom API URL, so unless a RawURL is also
+// specified files are fetched through the contents API. Passing the default
+// API URL (as GitHub Actions does through `GITHUB_API_URL`) changes nothing.
+func NewGitHubWithOptions(client http.IClient, options Options) *GitHub {
+	github := NewGitHub(client)
+
+	apiURL := strings.TrimSuffix(options.APIURL, "/")
+	if apiURL != "" && apiURL != defaultGitHubAPIURL {
+		github.APIURL = apiURL
+		github.RawURL = ""
+	}
+	if options.RawURL != "" {
+		github.RawURL = strings.TrimSuffix(options.RawURL, "/")
+	}
+
+	return github
+}
+
+// Name returns "github"
+func (github *GitHub) Name() string {
+	return "github"
+}
+
+// ListReleases fetches every page of a repo's releases
+func (github *GitHub) ListReleases(ctx context.Context, repo string) ([]Release, error) {
+	// e.g. https://api.github.com/repos/cyberark/secretless-broker/releases
+	releasesURL := fmt.Sprintf("%s/repos/%s/releases?per_page=100", github.APIURL, repo)
+
+	var releases []Release
+	err := getAllPages(ctx, github.Client, releasesURL, func(body []byte) error {
+		var pageReleases []githubRelease
+		err := json.Unmarshal(body, &pageReleases)
+		if err != nil {
+			return err
+		}
+
+		for _, release := range pageReleases {
+			releases = append(releases, Release(release))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// CompareRefs compares two refs using the compare API
+func (github *GitHub) CompareRefs(
+	ctx context.Context,
+	repo string,
+	fromRef string,
+	toRef string,
+) (*Comparison, error) {
+	// e.g. https://api.github.com/repos/cyberark/secretless-broker/compare/v1.5.2...HEAD
+	compareURL := fmt.Sprintf("%s/repos/%s/compare/%s...%s", github.APIURL, repo, fromRef, toRef)
+
+	comparison := githubComparison{}
+	err := getJSON(ctx, github.Client, compareURL, &comparison)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comparison{
+		URL:     comparison.URL,
+		AheadBy: comparison.AheadBy,
+	}, nil
+}
+
+// ListCommits lists the commits between two refs using the compare API, which
+// returns at most 250 of them, oldest first
+func (github *GitHub) ListCommits(
+	ctx context.Context,
+	repo string,
+	fromRef string,
+	toRef string,
+) ([]Commit, error) {
+	// e.g. https://api.github.com/repos/cyberark/secretless-broker/compare/v1.5.1...v1.5.2
+	compareURL := fmt.Sprintf("%s/repos/%s/compare/%s...%s", github.APIURL, repo, fromRef, toRef)
+
+	comparison := githubComparison{}
+	err := getJSON(ctx, github.Client, compareURL, &comparison)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]Commit, 0, len(comparison.Commits))
+	for _, commit := range comparison.Commits {
+		commits = append(commits, Commit{
+			SHA:     commit.SHA,
+			Message: commit.Commit.Message,
+			URL:     commit.URL,
+		})
+	}
+
+	return commits, nil
+}
+
+// BranchExists checks whether a branch with a specific name exists in the repo
+func (github *GitHub) BranchExists(ctx context.Context, repo string, branch string) (bool, error) {
+	// e.g. https://api.github.com/repos/cyberark/secretless-broker/branches/branchName
+	branchURL := fmt.Sprintf("%s/repos/%s/branches/%s", github.APIURL, repo, url.QueryEscape(branch))
+
+	return branchExists(ctx, github.Client, branchURL)
+}
+
+// FetchFile retrieves a file from the raw content host (raw.githubusercontent.com
+// by default) or, if there is none, through the contents API
+func (github *GitHub) FetchFile(ctx context.Context, repo string, ref string, path string) ([]byte, error) {
+	if github.RawURL == "" {
+		return github.fetchFileContents(ctx, repo, ref, path)
+	}
+
+	// e.g. https://raw.githubusercontent.com/cyberark/secretless-broker/master/CHANGELOG.md
+	fileURL := fmt.Sprintf("%s/%s/%s/%s", github.RawURL, repo, ref, path)
+
+	response, err := github.Client.Get(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Body, nil
+}
+
+// fetchFileContents retrieves a file through the contents API, which returns
+// it base64-encoded. Files over 1MB aren't supported by the API.
+func (github *GitHub) fetchFileContents(ctx context.Context, repo string, ref string, path string) ([]byte, error) {
+	// e.g. https://api.github.com/repos/cyberark/secretless-broker/contents/CHANGELOG.md?ref=master
+	contentsURL := fmt.Sprintf(
+		"%s/repos/%s/contents/%s?ref=%s",
+		github.APIURL,
+		repo,
+		path,
+		url.QueryEscape(ref),
+	)
+
+	content := githubContent{}
+	err := getJSON(ctx, github.Client, contentsURL, &content)
+	if err != nil {
+		return nil, err
+	}
+
+	if content.Encoding != "base64" {
+		return nil, fmt.Errorf("unsupported encoding '%s' of %s", content.Encoding, contentsURL)
+	}
+
+	// The content is wrapped over several lines
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+}
@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+func newTestGitHub(server string) *GitHub {
+	github := NewGitHub(http.NewClient())
+	github.APIURL = server
+	github.RawURL = server + "/raw"
+
+	return github
+}
+
+func TestGitHubReleaseParsing(t *testing.T) {
+	releaseJSON := testdataFile(t, "github", "release_v3.json")
+
+	var releaseInfo = githubRelease{}
+	err := json.Unmarshal([]byte(releaseJSON), &releaseInfo)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	description := releaseInfo.Description
+	assert.Regexp(t, regexp.MustCompile("^# Change log"), description)
+	assert.Regexp(t, regexp.MustCompile("\\(#1062\\)$"), description)
+
+	assert.Equal(t, releaseInfo.TagName, "v1.4.2")
+	assert.Equal(t, releaseInfo.Draft, false)
+	assert.Equal(t, releaseInfo.Name, "v1.4.2")
+}
+
+func TestGitHubListReleases(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/repos/cyberark/conjur-api-python3/releases?per_page=100": {
+			body: testdataFile(t, "github", "releases_v3.json"),
+		},
+	})
+	defer server.Close()
+
+	releases, err := newTestGitHub(server.URL).ListReleases(context.Background(), "cyberark/conjur-api-python3")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if !assert.Len(t, releases, 4) {
+		return
+	}
+	assert.Equal(t, Release{
+		Name:        "v0.1.1",
+		TagName:     "v0.1.1",
+		Description: releases[0].Description,
+		PublishedAt: time.Date(2020, time.November, 5, 21, 42, 8, 0, time.UTC),
+	}, releases[0])
+	assert.Equal(t, "v0.0.5", releases[1].TagName)
+	assert.True(t, releases[2].Prerelease)
+	assert.Equal(t, "v0.0.3", releases[3].Name)
+}
+
+func TestGitHubListReleasesPagination(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/repos/org/repo/releases?per_page=100": {
+			link: `<{{server}}/releases?page=2>; rel="next", <{{server}}/releases?page=3>; rel="last"`,
+			body: `[{"name": "v1.0.2"}, {"name": "v1.0.1"}]`,
+		},
+		"/releases?page=2": {
+			link: `<{{server}}/releases?page=3>; rel="next", <{{server}}/repos/org/repo/releases?per_page=100>; rel="first"`,
+			body: `[{"name": "v1.0.0"}, {"name": "v0.9.0-rc1", "prerelease": true}]`,
+		},
+		"/releases?page=3": {
+			body: `[{"name": "v0.9.0"}]`,
+		},
+	})
+	defer server.Close()
+
+	releases, err := newTestGitHub(server.URL).ListReleases(context.Background(), "org/repo")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	names := []string{}
+	for _, release := range releases {
+		names = append(names, release.Name)
+	}
+	assert.Equal(t, []string{"v1.0.2", "v1.0.1", "v1.0.0", "v0.9.0-rc1", "v0.9.0"}, names)
+}
+
+func TestGitHubListReleasesPaginationLoop(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		// Always point back at ourselves
+		"/repos/org/repo/releases?per_page=100": {
+			link: `<{{server}}/repos/org/repo/releases?per_page=100>; rel="next"`,
+			body: `[{"name": "v1.0.0"}]`,
+		},
+	})
+	defer server.Close()
+
+	releases, err := newTestGitHub(server.URL).ListReleases(context.Background(), "org/repo")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Release{{Name: "v1.0.0"}}, releases)
+}
+
+func TestGitHubListReleasesUnmarshalingProblem(t *testing.T) {
+	// release_v3.json (vs releases_v3.json) should fail unmarshaling since it's
+	// not an array
+	server := newFakeServer(map[string]fakeResponse{
+		"/repos/org/repo/releases?per_page=100": {
+			body: testdataFile(t, "github", "release_v3.json"),
+		},
+	})
+	defer server.Close()
+
+	_, err := newTestGitHub(server.URL).ListReleases(context.Background(), "org/repo")
+	assert.EqualError(
+		t,
+		err,
+		"json: cannot unmarshal object into Go value of type []provider.githubRelease",
+	)
+}
+
+func TestGitHubCompareRefs(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/repos/octocat/Hello-World/compare/master...topic": {
+			body: testdataFile(t, "github", "compare_v3.json"),
+		},
+	})
+	defer server.Close()
+
+	comparison, err := newTestGitHub(server.URL).CompareRefs(
+		context.Background(),
+		"octocat/Hello-World",
+		"master",
+		"topic",
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, &Comparison{
+		URL:     "https://github.com/octocat/Hello-World/compare/master...topic",
+		AheadBy: 1,
+	}, comparison)
+}
+
+func TestGitHubListCommits(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/repos/octocat/Hello-World/compare/master...topic": {
+			body: testdataFile(t, "github", "compare_v3.json"),
+		},
+	})
+	defer server.Close()
+
+	commits, err := newTestGitHub(server.URL).ListCommits(
+		context.Background(),
+		"octocat/Hello-World",
+		"master",
+		"topic",
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Commit{
+		{
+			SHA:     "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+			Message: "Fix all the bugs",
+			URL:     "https://github.com/octocat/Hello-World/commit/6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		},
+	}, commits)
+}
+
+func TestGitHubBranchExists(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/repos/org/repo/branches/release%2F1.0": {
+			body: `{"name": "release/1.0"}`,
+		},
+		"/repos/org/repo/branches/main": {
+			status: 404,
+			body:   `{"message":"Branch not found"}`,
+		},
+		"/repos/org/private/branches/main": {
+			status: 401,
+			body:   `{"message":"Requires authentication"}`,
+		},
+	})
+	defer server.Close()
+
+	github := newTestGitHub(server.URL)
+
+	exists, err := github.BranchExists(context.Background(), "org/repo", "release/1.0")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = github.BranchExists(context.Background(), "org/repo", "main")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = github.BranchExists(context.Background(), "org/private", "main")
+	assert.Error(t, err)
+}
+
+func TestGitHubFetchFile(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/raw/org/repo/release/1.0/CHANGELOG.md": {
+			body: "# Changelog\n",
+		},
+	})
+	defer server.Close()
+
+	contents, err := newTestGitHub(server.URL).FetchFile(context.Background(), "org/repo", "release/1.0", "CHANGELOG.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "# Changelog\n", string(contents))
+}
+
+func TestNewGitHubWithOptions(t *testing.T) {
+	github := NewGitHubWithOptions(http.NewClient(), Options{})
+	assert.Equal(t, "https://api.github.com", github.APIURL)
+	assert.Equal(t, "https://raw.githubusercontent.com", github.RawURL)
+
+	github = NewGitHubWithOptions(http.NewClient(), Options{APIURL: "https://api.github.com/"})
+	assert.Equal(t, "https://api.github.com", github.APIURL)
+	assert.Equal(t, "https://raw.githubusercontent.com", github.RawURL)
+
+	// A custom API URL alone switches files over to the contents API
+	github = NewGitHubWithOptions(http.NewClient(), Options{APIURL: "https://ghe.example.com/api/v3/"})
+	assert.Equal(t, "https://ghe.example.com/api/v3", github.APIURL)
+	assert.Equal(t, "", github.RawURL)
+
+	github = NewGitHubWithOptions(http.NewClient(), Options{
+		APIURL: "https://ghe.example.com/api/v3",
+		RawURL: "https://ghe.example.com/raw",
+	})
+	assert.Equal(t, "https://ghe.example.com/api/v3", github.APIURL)
+	assert.Equal(t, "https://ghe.example.com/raw", github.RawURL)
+}
+
+func TestGitHubFetchFileThroughContentsAPI(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/v3/repos/org/repo/contents/CHANGELOG.md?ref=release%2F1.0": {
+			// Base64 content is wrapped like the real API does
+			body: `{"encoding": "base64", "content": "IyBDaGFu\nZ2Vsb2cK\n"}`,
+		},
+		"/api/v3/repos/org/repo/contents/HUGE.md?ref=main": {
+			body: `{"encoding": "none", "content": ""}`,
+		},
+	})
+	defer server.Close()
+
+	github := NewGitHubWithOptions(http.NewClient(), Options{APIURL: server.URL + "/api/v3"})
+
+	contents, err := github.FetchFile(context.Background(), "org/repo", "release/1.0", "CHANGELOG.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "# Changelog\n", string(contents))
+
+	_, err = github.FetchFile(context.Background(), "org/repo", "main", "HUGE.md")
+	assert.Contains(t, err.Error(), "unsupported encoding 'none'")
+}
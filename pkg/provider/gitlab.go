@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+const defaultGitLabURL = "https://gitlab.com"
+
+// gitlabRelease is a trimmed representation of a GitLab v4 API release
+type gitlabRelease struct {
+	Description     string    `json:"description"`
+	Name            string    `json:"name"`
+	TagName         string    `json:"tag_name"`
+	UpcomingRelease bool      `json:"upcoming_release"`
+	ReleasedAt      time.Time `json:"released_at"`
+}
+
+// gitlabComparison is a trimmed representation of a GitLab v4 API comparison
+type gitlabComparison struct {
+	Commits []json.RawMessage `json:"commits"`
+	WebURL  string            `json:"web_url"`
+}
+
+// GitLab is the Provider for gitlab.com and self-managed GitLab instances
+type GitLab struct {
+	Client http.IClient
+	URL    string
+}
+
+// NewGitLab creates a GitLab provider for gitlab.com
+func NewGitLab(client http.IClient) *GitLab {
+	return &GitLab{
+		Client: client,
+		URL:    defaultGitLabURL,
+	}
+}
+
+// NewGitLabWithOptions creates a GitLab provider for the instance at
+// options.BaseURL, e.g. `https://gitlab.example.com` for a self-managed one
+func NewGitLabWithOptions(client http.IClient, options Options) *GitLab {
+	gitlab := NewGitLab(client)
+	if options.BaseURL != "" {
+		gitlab.URL = strings.TrimSuffix(options.BaseURL, "/")
+	}
+
+	return gitlab
+}
+
+// Name returns "gitlab"
+func (gitlab *GitLab) Name() string {
+	return "gitlab"
+}
+
+// projectURL returns the API URL of a project. GitLab identifies projects by
+// their URL-encoded path, e.g. `cyberark%2Fconjur`.
+func (gitlab *GitLab) projectURL(repo string) string {
+	return fmt.Sprintf("%s/api/v4/projects/%s", gitlab.URL, url.QueryEscape(repo))
+}
+
+// ListReleases fetches every page of a project's releases. Upcoming releases
+// (ones with a release date in the future) are reported as prereleases.
+func (gitlab *GitLab) ListReleases(ctx context.Context, repo string) ([]Release, error) {
+	releasesURL := gitlab.projectURL(repo) + "/releases?per_page=100"
+
+	var releases []Release
+	err := getAllPages(ctx, gitlab.Client, releasesURL, func(body []byte) error {
+		var pageReleases []gitlabRelease
+		err := json.Unmarshal(body, &pageReleases)
+		if err != nil {
+			return err
+		}
+
+		for _, release := range pageReleases {
+			releases = append(releases, Release{
+				Description: release.Description,
+				Name:        release.Name,
+				TagName:     release.TagName,
+				Prerelease:  release.UpcomingRelease,
+				PublishedAt: release.ReleasedAt,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// CompareRefs compares two refs using the repository compare API
+func (gitlab *GitLab) CompareRefs(
+	ctx context.Context,
+	repo string,
+	fromRef string,
+	toRef string,
+) (*Comparison, error) {
+	compareURL := fmt.Sprintf(
+		"%s/repository/compare?from=%s&to=%s",
+		gitlab.projectURL(repo),
+		url.QueryEscape(fromRef),
+		url.QueryEscape(toRef),
+	)
+
+	comparison := gitlabComparison{}
+	err := getJSON(ctx, gitlab.Client, compareURL, &comparison)
+	if err != nil {
+		return nil, err
+	}
+
+	// Older GitLab versions don't include the web URL
+	webURL := comparison.WebURL
+	if webURL == "" {
+		webURL = fmt.Sprintf("%s/%s/-/compare/%s...%s", gitlab.URL, repo, fromRef, toRef)
+	}
+
+	return &Comparison{
+		URL:     webURL,
+		AheadBy: len(comparison.Commits),
+	}, nil
+}
+
+// BranchExists checks whether a branch with a specific name exists in the
+// project
+func (gitlab *GitLab) BranchExists(ctx context.Context, repo string, branch string) (bool, error) {
+	branchURL := gitlab.projectURL(repo) + "/repository/branches/" + url.QueryEscape(branch)
+
+	return branchExists(ctx, gitlab.Client, branchURL)
+}
+
+// FetchFile retrieves a file using the repository files API
+func (gitlab *GitLab) FetchFile(ctx context.Context, repo string, ref string, path string) ([]byte, error) {
+	fileURL := fmt.Sprintf(
+		"%s/repository/files/%s/raw?ref=%s",
+		gitlab.projectURL(repo),
+		url.QueryEscape(path),
+		url.QueryEscape(ref),
+	)
+
+	response, err := gitlab.Client.Get(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Body, nil
+}
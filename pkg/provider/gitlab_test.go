@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+func newTestGitLab(server string) *GitLab {
+	gitlab := NewGitLab(http.NewClient())
+	gitlab.URL = server
+
+	return gitlab
+}
+
+func TestGitLabListReleases(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/v4/projects/mirrors%2Fconjur/releases?per_page=100": {
+			link: `<{{server}}/api/v4/projects/mirrors%2Fconjur/releases?page=2&per_page=100>; rel="next"`,
+			body: `[
+				{"name": "v1.1.0", "tag_name": "v1.1.0", "description": "Next", "upcoming_release": true},
+				{"name": "v1.0.1", "tag_name": "v1.0.1", "description": "Fixes", "released_at": "2020-11-05T21:42:08Z"}
+			]`,
+		},
+		"/api/v4/projects/mirrors%2Fconjur/releases?page=2&per_page=100": {
+			body: `[{"name": "v1.0.0", "tag_name": "v1.0.0", "description": "First"}]`,
+		},
+	})
+	defer server.Close()
+
+	releases, err := newTestGitLab(server.URL).ListReleases(context.Background(), "mirrors/conjur")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Release{
+		{Name: "v1.1.0", TagName: "v1.1.0", Description: "Next", Prerelease: true},
+		{
+			Name:        "v1.0.1",
+			TagName:     "v1.0.1",
+			Description: "Fixes",
+			PublishedAt: time.Date(2020, time.November, 5, 21, 42, 8, 0, time.UTC),
+		},
+		{Name: "v1.0.0", TagName: "v1.0.0", Description: "First"},
+	}, releases)
+}
+
+func TestGitLabCompareRefs(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/v4/projects/mirrors%2Fconjur/repository/compare?from=v1.0.1&to=HEAD": {
+			body: `{
+				"commits": [{"id": "abc"}, {"id": "def"}],
+				"web_url": "{{server}}/mirrors/conjur/-/compare/v1.0.1...HEAD"
+			}`,
+		},
+		"/api/v4/projects/mirrors%2Fconjur/repository/compare?from=v1.0.0&to=HEAD": {
+			body: `{"commits": []}`,
+		},
+	})
+	defer server.Close()
+
+	gitlab := newTestGitLab(server.URL)
+
+	comparison, err := gitlab.CompareRefs(context.Background(), "mirrors/conjur", "v1.0.1", "HEAD")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &Comparison{
+		URL:     server.URL + "/mirrors/conjur/-/compare/v1.0.1...HEAD",
+		AheadBy: 2,
+	}, comparison)
+
+	// The web URL is built by hand for GitLab versions that don't return it
+	comparison, err = gitlab.CompareRefs(context.Background(), "mirrors/conjur", "v1.0.0", "HEAD")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &Comparison{
+		URL:     server.URL + "/mirrors/conjur/-/compare/v1.0.0...HEAD",
+		AheadBy: 0,
+	}, comparison)
+}
+
+func TestGitLabBranchExists(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/v4/projects/mirrors%2Fconjur/repository/branches/release%2F1.0": {
+			body: `{"name": "release/1.0"}`,
+		},
+	})
+	defer server.Close()
+
+	gitlab := newTestGitLab(server.URL)
+
+	exists, err := gitlab.BranchExists(context.Background(), "mirrors/conjur", "release/1.0")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = gitlab.BranchExists(context.Background(), "mirrors/conjur", "main")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestGitLabFetchFile(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/v4/projects/mirrors%2Fconjur/repository/files/CHANGELOG.md/raw?ref=release%2F1.0": {
+			body: "# Changelog\n",
+		},
+	})
+	defer server.Close()
+
+	contents, err := newTestGitLab(server.URL).FetchFile(context.Background(), "mirrors/conjur", "release/1.0", "CHANGELOG.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "# Changelog\n", string(contents))
+}
@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const defaultLocalWebURL = "https://github.com"
+
+// Local is a Provider that reads from local git clones instead of an API, so
+// it needs neither network access nor a token. Tags are treated as releases.
+//
+// A repo such as `cyberark/conjur` is looked up as `<Dir>/cyberark/conjur`,
+// falling back to `<Dir>/conjur`. Comparison URLs point at WebURL (GitHub by
+// default) so that the generated notes match the ones built from the API.
+type Local struct {
+	Dir    string
+	WebURL string
+}
+
+// NewLocal creates a Local provider for the clones in a directory
+func NewLocal(dir string) *Local {
+	return &Local{
+		Dir:    dir,
+		WebURL: defaultLocalWebURL,
+	}
+}
+
+// Name returns "local"
+func (local *Local) Name() string {
+	return "local"
+}
+
+// cloneDir finds the clone of a repo
+func (local *Local) cloneDir(repo string) (string, error) {
+	for _, dir := range []string{
+		filepath.Join(local.Dir, filepath.FromSlash(repo)),
+		filepath.Join(local.Dir, path.Base(repo)),
+	} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("no clone of %s found in %s", repo, local.Dir)
+}
+
+// git runs a git command in the clone of a repo and returns its output
+func (local *Local) git(ctx context.Context, repo string, args ...string) ([]byte, error) {
+	dir, err := local.cloneDir(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	err = command.Run()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"git %s failed in %s: %v: %s",
+			strings.Join(args, " "),
+			dir,
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// hasRef checks whether a fully qualified ref exists in the clone
+func (local *Local) hasRef(ctx context.Context, repo string, ref string) (bool, error) {
+	// `rev-parse --verify --quiet` fails without output for missing refs, so
+	// any other failure (e.g. a missing clone) has to be checked for first
+	if _, err := local.cloneDir(repo); err != nil {
+		return false, err
+	}
+
+	_, err := local.git(ctx, repo, "rev-parse", "--verify", "--quiet", ref)
+	return err == nil, nil
+}
+
+// resolveRef maps a branch name to a ref that exists in the clone. Fresh
+// clones only have a local branch for the default branch, so the branches of
+// `origin` are checked too. Anything else (tags, SHAs) is used as-is.
+func (local *Local) resolveRef(ctx context.Context, repo string, ref string) (string, error) {
+	for _, candidate := range []string{"refs/heads/" + ref, "refs/remotes/origin/" + ref} {
+		exists, err := local.hasRef(ctx, repo, candidate)
+		if err != nil {
+			return "", err
+		}
+
+		if exists {
+			return candidate, nil
+		}
+	}
+
+	return ref, nil
+}
+
+// ListReleases lists the tags of the clone, newest version first
+func (local *Local) ListReleases(ctx context.Context, repo string) ([]Release, error) {
+	output, err := local.git(ctx, repo, "tag", "--list", "--sort=-version:refname")
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []Release
+	for _, tag := range strings.Fields(string(output)) {
+		releases = append(releases, Release{
+			Name:    tag,
+			TagName: tag,
+		})
+	}
+
+	return releases, nil
+}
+
+// CompareRefs counts the commits reachable from `toRef` but not from `fromRef`
+func (local *Local) CompareRefs(
+	ctx context.Context,
+	repo string,
+	fromRef string,
+	toRef string,
+) (*Comparison, error) {
+	output, err := local.git(ctx, repo, "rev-list", "--count", fromRef+".."+toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	aheadBy, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return nil, fmt.Errorf("unexpected output of git rev-list: %v", err)
+	}
+
+	return &Comparison{
+		URL:     fmt.Sprintf("%s/%s/compare/%s...%s", local.WebURL, repo, fromRef, toRef),
+		AheadBy: aheadBy,
+	}, nil
+}
+
+// ListCommits lists the commits reachable from `toRef` but not from `fromRef`
+// with `git log`, oldest first
+func (local *Local) ListCommits(
+	ctx context.Context,
+	repo string,
+	fromRef string,
+	toRef string,
+) ([]Commit, error) {
+	resolvedFromRef, err := local.resolveRef(ctx, repo, fromRef)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedToRef, err := local.resolveRef(ctx, repo, toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commits are separated by a record separator and the SHA from the
+	// message by a unit separator, neither of which show up in messages
+	output, err := local.git(ctx, repo, "log", "--reverse", "--format=%H%x1f%B%x1e", resolvedFromRef+".."+resolvedToRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		commits = append(commits, Commit{
+			SHA:     fields[0],
+			Message: strings.TrimSpace(fields[1]),
+			URL:     fmt.Sprintf("%s/%s/commit/%s", local.WebURL, repo, fields[0]),
+		})
+	}
+
+	return commits, nil
+}
+
+// BranchExists checks for a local branch or a branch of `origin`
+func (local *Local) BranchExists(ctx context.Context, repo string, branch string) (bool, error) {
+	ref, err := local.resolveRef(ctx, repo, branch)
+	if err != nil {
+		return false, err
+	}
+
+	return ref != branch, nil
+}
+
+// FetchFile reads a file at a specific ref with `git show`
+func (local *Local) FetchFile(ctx context.Context, repo string, ref string, path string) ([]byte, error) {
+	resolvedRef, err := local.resolveRef(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return local.git(ctx, repo, "show", resolvedRef+":"+path)
+}
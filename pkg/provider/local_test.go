@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runGit runs a git command with a fixed identity, failing the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	command := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	command.Env = append(
+		os.Environ(),
+		"GIT_AUTHOR_NAME=Test",
+		"GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test",
+		"GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_NOSYSTEM=1",
+	)
+
+	output, err := command.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, output)
+	}
+}
+
+func commitChangelog(t *testing.T, dir string, changelog string) {
+	err := ioutil.WriteFile(filepath.Join(dir, "CHANGELOG.md"), []byte(changelog), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, dir, "add", "CHANGELOG.md")
+	runGit(t, dir, "commit", "--quiet", "-m", "Update CHANGELOG")
+}
+
+// newTestClones creates a repo with a few tags and a release branch, and
+// clones it into `<clonesDir>/cyberark/widget`
+func newTestClones(t *testing.T) (string, func()) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	baseDir, err := ioutil.TempDir("", "local_provider_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originDir := filepath.Join(baseDir, "origin")
+	if err := os.MkdirAll(originDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, originDir, "init", "--quiet")
+	runGit(t, originDir, "checkout", "--quiet", "-b", "master")
+
+	commitChangelog(t, originDir, "# Changelog\n\n## [1.0.0] - 2020-01-01\n")
+	runGit(t, originDir, "tag", "v1.0.0")
+	commitChangelog(t, originDir, "# Changelog\n\n## [1.1.0] - 2020-02-01\n")
+	runGit(t, originDir, "tag", "v1.1.0")
+	runGit(t, originDir, "tag", "not-a-version")
+
+	runGit(t, originDir, "branch", "release/1.1")
+
+	commitChangelog(t, originDir, "# Changelog\n\n## [Unreleased]\n")
+	commitChangelog(t, originDir, "# Changelog\n\n## [Unreleased]\n- More\n")
+
+	clonesDir := filepath.Join(baseDir, "clones")
+	if err := os.MkdirAll(filepath.Join(clonesDir, "cyberark"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, baseDir, "clone", "--quiet", originDir, filepath.Join(clonesDir, "cyberark", "widget"))
+
+	return clonesDir, func() { os.RemoveAll(baseDir) }
+}
+
+func TestLocalListReleases(t *testing.T) {
+	clonesDir, cleanup := newTestClones(t)
+	defer cleanup()
+
+	releases, err := NewLocal(clonesDir).ListReleases(context.Background(), "cyberark/widget")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Release{
+		{Name: "v1.1.0", TagName: "v1.1.0"},
+		{Name: "v1.0.0", TagName: "v1.0.0"},
+		{Name: "not-a-version", TagName: "not-a-version"},
+	}, releases)
+}
+
+func TestLocalCompareRefs(t *testing.T) {
+	clonesDir, cleanup := newTestClones(t)
+	defer cleanup()
+
+	comparison, err := NewLocal(clonesDir).CompareRefs(context.Background(), "cyberark/widget", "v1.1.0", "HEAD")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, &Comparison{
+		URL:     "https://github.com/cyberark/widget/compare/v1.1.0...HEAD",
+		AheadBy: 2,
+	}, comparison)
+}
+
+func TestLocalListCommits(t *testing.T) {
+	clonesDir, cleanup := newTestClones(t)
+	defer cleanup()
+
+	commits, err := NewLocal(clonesDir).ListCommits(context.Background(), "cyberark/widget", "v1.0.0", "release/1.1")
+	if !assert.NoError(t, err) || !assert.Len(t, commits, 1) {
+		return
+	}
+
+	assert.Equal(t, "Update CHANGELOG", commits[0].Message)
+	assert.Len(t, commits[0].SHA, 40)
+	assert.Equal(t, "https://github.com/cyberark/widget/commit/"+commits[0].SHA, commits[0].URL)
+
+	commits, err = NewLocal(clonesDir).ListCommits(context.Background(), "cyberark/widget", "v1.1.0", "HEAD")
+	if assert.NoError(t, err) {
+		assert.Len(t, commits, 2)
+	}
+}
+
+func TestLocalBranchExists(t *testing.T) {
+	clonesDir, cleanup := newTestClones(t)
+	defer cleanup()
+
+	local := NewLocal(clonesDir)
+
+	for branch, expected := range map[string]bool{
+		"master":      true,
+		"release/1.1": true,
+		"main":        false,
+	} {
+		exists, err := local.BranchExists(context.Background(), "cyberark/widget", branch)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, exists, branch)
+	}
+}
+
+func TestLocalFetchFile(t *testing.T) {
+	clonesDir, cleanup := newTestClones(t)
+	defer cleanup()
+
+	local := NewLocal(clonesDir)
+
+	contents, err := local.FetchFile(context.Background(), "cyberark/widget", "master", "CHANGELOG.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "# Changelog\n\n## [Unreleased]\n- More\n", string(contents))
+
+	// Release branches usually only exist on origin
+	contents, err = local.FetchFile(context.Background(), "cyberark/widget", "release/1.1", "CHANGELOG.md")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "# Changelog\n\n## [1.1.0] - 2020-02-01\n", string(contents))
+
+	_, err = local.FetchFile(context.Background(), "cyberark/widget", "master", "MISSING.md")
+	assert.Error(t, err)
+}
+
+func TestLocalFindsClonesByBaseName(t *testing.T) {
+	clonesDir, cleanup := newTestClones(t)
+	defer cleanup()
+
+	releases, err := NewLocal(filepath.Join(clonesDir, "cyberark")).ListReleases(context.Background(), "cyberark/widget")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, releases, 3)
+}
+
+func TestLocalMissingClone(t *testing.T) {
+	clonesDir, cleanup := newTestClones(t)
+	defer cleanup()
+
+	local := NewLocal(clonesDir)
+
+	_, err := local.ListReleases(context.Background(), "cyberark/missing")
+	assert.EqualError(t, err, "no clone of cyberark/missing found in "+clonesDir)
+
+	_, err = local.BranchExists(context.Background(), "cyberark/missing", "main")
+	assert.Error(t, err)
+}
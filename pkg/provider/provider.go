@@ -0,0 +1,209 @@
+// Package provider abstracts the source code hosting services (GitHub, GitLab,
+// etc.) that suite components live on
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+// Release is a published release of a repository
+type Release struct {
+	Description string
+	Draft       bool
+	Name        string
+	TagName     string
+	Prerelease  bool
+	// PublishedAt is zero if the service doesn't record it
+	PublishedAt time.Time
+}
+
+// Comparison describes how far one ref is ahead of another. URL points at a
+// human-readable view of the differences.
+type Comparison struct {
+	URL     string
+	AheadBy int
+}
+
+// Provider is the interface to a source code hosting service. Repos are
+// identified by their path on the service, e.g. `cyberark/conjur`.
+type Provider interface {
+	// Name returns the name used to select the provider in suite.yml
+	Name() string
+
+	// ListReleases returns every release of a repo, newest first
+	ListReleases(ctx context.Context, repo string) ([]Release, error)
+
+	// CompareRefs compares `toRef` against `fromRef`
+	CompareRefs(ctx context.Context, repo string, fromRef string, toRef string) (*Comparison, error)
+
+	// BranchExists checks whether a repo has a branch with a specific name
+	BranchExists(ctx context.Context, repo string, branch string) (bool, error)
+
+	// FetchFile retrieves the raw contents of a file at a specific ref
+	FetchFile(ctx context.Context, repo string, ref string, path string) ([]byte, error)
+}
+
+// Commit is a commit of a repository. URL points at a human-readable view of
+// it.
+type Commit struct {
+	SHA     string
+	Message string
+	URL     string
+}
+
+// CommitLister is implemented by the providers that can list the commits
+// between two refs
+type CommitLister interface {
+	// ListCommits returns the commits reachable from `toRef` but not from
+	// `fromRef`, oldest first
+	ListCommits(ctx context.Context, repo string, fromRef string, toRef string) ([]Commit, error)
+}
+
+// DefaultProvider is used for repos that don't specify one
+const DefaultProvider = "github"
+
+// Options customizes a provider. Empty values keep the defaults.
+//
+// The API and raw URLs are only supported by the GitHub provider, e.g. for
+// GitHub Enterprise. BaseURL is the web URL of the instance that the GitLab,
+// Gitea and Bitbucket providers talk to, e.g. `https://gitlab.example.com` for
+// a self-managed GitLab. CloneDir is the directory of clones that the local
+// provider reads from, and is required by it.
+type Options struct {
+	APIURL   string
+	RawURL   string
+	BaseURL  string
+	CloneDir string
+}
+
+// IsGitHub checks whether a provider name (as used in suite.yml) selects the
+// GitHub provider
+func IsGitHub(name string) bool {
+	return name == "" || strings.EqualFold(name, "github")
+}
+
+var constructors = map[string]func(client http.IClient, options Options) (Provider, error){
+	"bitbucket": func(client http.IClient, options Options) (Provider, error) {
+		return NewBitbucketWithOptions(client, options), nil
+	},
+	"gitea": func(client http.IClient, options Options) (Provider, error) {
+		return NewGiteaWithOptions(client, options), nil
+	},
+	"github": func(client http.IClient, options Options) (Provider, error) {
+		return NewGitHubWithOptions(client, options), nil
+	},
+	"gitlab": func(client http.IClient, options Options) (Provider, error) {
+		return NewGitLabWithOptions(client, options), nil
+	},
+	"local": func(client http.IClient, options Options) (Provider, error) {
+		if options.CloneDir == "" {
+			return nil, fmt.Errorf("the local provider requires a clone directory")
+		}
+
+		return NewLocal(options.CloneDir), nil
+	},
+}
+
+// Names returns the names of all supported providers, sorted
+func Names() []string {
+	names := make([]string, 0, len(constructors))
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// New creates the provider with the specified name. An empty name selects the
+// DefaultProvider.
+func New(name string, client http.IClient, options Options) (Provider, error) {
+	if name == "" {
+		name = DefaultProvider
+	}
+
+	constructor, ok := constructors[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf(
+			"unknown provider '%s' (expected one of: %s)",
+			name,
+			strings.Join(Names(), ", "),
+		)
+	}
+
+	if options.CloneDir != "" && !strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("a clone directory is only supported by the local provider, not '%s'", name)
+	}
+
+	if (options.APIURL != "" || options.RawURL != "") && !IsGitHub(name) {
+		return nil, fmt.Errorf("custom API and raw URLs are only supported by the github provider, not '%s'", name)
+	}
+
+	if options.BaseURL != "" && (IsGitHub(name) || strings.EqualFold(name, "local")) {
+		return nil, fmt.Errorf("a base URL is only supported by the bitbucket, gitea and gitlab providers, not '%s'", name)
+	}
+
+	return constructor(client, options)
+}
+
+// getJSON fetches a URL and unmarshals the response into `value`
+func getJSON(ctx context.Context, client http.IClient, url string, value interface{}) error {
+	response, err := client.Get(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(response.Body, value)
+}
+
+// getAllPages fetches every page of a listing, following the
+// `Link: rel="next"` header until the last page is reached. Each page body is
+// handed to `addPage`.
+func getAllPages(
+	ctx context.Context,
+	client http.IClient,
+	firstPageURL string,
+	addPage func(body []byte) error,
+) error {
+	// Guard against servers that link back to a page we've already seen
+	visitedURLs := map[string]bool{}
+	for pageURL := firstPageURL; pageURL != "" && !visitedURLs[pageURL]; {
+		visitedURLs[pageURL] = true
+
+		response, err := client.Get(ctx, pageURL)
+		if err != nil {
+			return err
+		}
+
+		err = addPage(response.Body)
+		if err != nil {
+			return err
+		}
+
+		pageURL = response.NextPageURL()
+	}
+
+	return nil
+}
+
+// branchExists checks for a branch by fetching its API URL, treating a
+// `404 Not Found` as a missing branch
+func branchExists(ctx context.Context, client http.IClient, branchURL string) (bool, error) {
+	_, err := client.Get(ctx, branchURL)
+	if err != nil {
+		if http.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
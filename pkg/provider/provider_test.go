@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"io/ioutil"
+	stdlibHttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/http"
+)
+
+// fakeResponse is a canned response of a fake server. Any `{{server}}` in the
+// body or Link header is replaced with the URL of the server.
+type fakeResponse struct {
+	status int
+	link   string
+	body   string
+}
+
+// newFakeServer serves canned responses keyed by request URI (path and query,
+// as sent on the wire). Unknown URIs get a `404 Not Found`.
+func newFakeServer(routes map[string]fakeResponse) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(stdlibHttp.HandlerFunc(func(rw stdlibHttp.ResponseWriter, req *stdlibHttp.Request) {
+		response, ok := routes[req.URL.RequestURI()]
+		if !ok {
+			rw.WriteHeader(stdlibHttp.StatusNotFound)
+			rw.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+
+		if response.link != "" {
+			rw.Header().Set("Link", strings.ReplaceAll(response.link, "{{server}}", server.URL))
+		}
+		if response.status != 0 {
+			rw.WriteHeader(response.status)
+		}
+		rw.Write([]byte(strings.ReplaceAll(response.body, "{{server}}", server.URL)))
+	}))
+
+	return server
+}
+
+// testdataFile reads a fixture from the testdata directory
+func testdataFile(t *testing.T, path ...string) string {
+	contents, err := ioutil.ReadFile(filepath.Join(append([]string{"testdata"}, path...)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(contents)
+}
+
+func TestNew(t *testing.T) {
+	client := http.NewClient()
+
+	for _, name := range []string{"bitbucket", "gitea", "github", "gitlab", "GitLab"} {
+		source, err := New(name, client, Options{})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, strings.ToLower(name), source.Name())
+	}
+
+	source, err := New("", client, Options{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, DefaultProvider, source.Name())
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+	_, err := New("sourceforge", http.NewClient(), Options{})
+	assert.EqualError(
+		t,
+		err,
+		"unknown provider 'sourceforge' (expected one of: bitbucket, gitea, github, gitlab, local)",
+	)
+}
+
+func TestNewWithOptionsOnlyForGitHub(t *testing.T) {
+	options := Options{APIURL: "https://ghe.example.com/api/v3"}
+
+	source, err := New("github", http.NewClient(), options)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "https://ghe.example.com/api/v3", source.(*GitHub).APIURL)
+
+	_, err = New("gitlab", http.NewClient(), options)
+	assert.EqualError(t, err, "custom API and raw URLs are only supported by the github provider, not 'gitlab'")
+}
+
+func TestNewWithBaseURL(t *testing.T) {
+	server := newFakeServer(map[string]fakeResponse{
+		"/api/2.0/repositories/mirrors/conjur/refs/tags?pagelen=100&sort=-target.date": {
+			body: `{"values": [{"name": "v1.0.0"}]}`,
+		},
+		"/api/v1/repos/mirrors/conjur/releases?limit=50": {
+			body: `[{"name": "v1.0.0", "tag_name": "v1.0.0"}]`,
+		},
+		"/api/v4/projects/mirrors%2Fconjur/releases?per_page=100": {
+			body: `[{"name": "v1.0.0", "tag_name": "v1.0.0"}]`,
+		},
+	})
+	defer server.Close()
+
+	for _, name := range []string{"bitbucket", "gitea", "gitlab"} {
+		source, err := New(name, http.NewClient(), Options{BaseURL: server.URL + "/"})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		releases, err := source.ListReleases(context.Background(), "mirrors/conjur")
+		if !assert.NoError(t, err, name) {
+			return
+		}
+		assert.Equal(t, []Release{{Name: "v1.0.0", TagName: "v1.0.0"}}, releases, name)
+	}
+
+	_, err := New("github", http.NewClient(), Options{BaseURL: server.URL})
+	assert.EqualError(t, err, "a base URL is only supported by the bitbucket, gitea and gitlab providers, not 'github'")
+}
+
+func TestNewLocal(t *testing.T) {
+	source, err := New("local", nil, Options{CloneDir: "/src"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "/src", source.(*Local).Dir)
+
+	_, err = New("local", nil, Options{})
+	assert.EqualError(t, err, "the local provider requires a clone directory")
+
+	_, err = New("github", nil, Options{CloneDir: "/src"})
+	assert.EqualError(t, err, "a clone directory is only supported by the local provider, not 'github'")
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 
 	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
+	"github.com/cyberark/conjur-oss-suite-release/pkg/provider"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,14 +17,48 @@ type describedObject struct {
 
 // Repository represents a codified description of a target component
 type Repository struct {
-	describedObject    `yaml:",inline"`
-	URL                string
+	describedObject `yaml:",inline"`
+	URL             string
+	Provider        string `yaml:"provider,omitempty"`
+	APIURL          string `yaml:"api_url,omitempty"`
+	RawURL          string `yaml:"raw_url,omitempty"`
+	// BaseURL is the instance that a GitLab, Gitea or Bitbucket repo is hosted
+	// on, e.g. `https://gitlab.example.com`. Defaults to the public service.
+	BaseURL            string `yaml:"base_url,omitempty"`
+	CloneDir           string `yaml:"clone_dir,omitempty"`
 	CertificationLevel string `yaml:"certification,omitempty"`
 	Version            string `yaml:"version,omitempty"`
 	AfterVersion       string `yaml:"after,omitempty"`
 	UpgradeURL         string `yaml:"upgrade_url,omitempty"`
+	// ReleaseBodies says when release descriptions are used as changelogs,
+	// see ReleaseBodiesIgnore. Defaults to that of the section.
+	ReleaseBodies string `yaml:"release_bodies,omitempty"`
+	// ChangelogSource is where the changes of each version are read from, see
+	// ChangelogSourceFile
+	ChangelogSource string `yaml:"changelog_source,omitempty"`
 }
 
+// Where the changes of each version of a repo are read from
+const (
+	// ChangelogSourceFile reads them from CHANGELOG.md. This is the default.
+	ChangelogSourceFile = "changelog"
+	// ChangelogSourceCommits synthesizes them from the Conventional Commits
+	// between release tags, for repos without a CHANGELOG
+	ChangelogSourceCommits = "commits"
+)
+
+// When release descriptions are used as the changelog of a version
+const (
+	// ReleaseBodiesIgnore only uses the CHANGELOG. This is the default.
+	ReleaseBodiesIgnore = "ignore"
+	// ReleaseBodiesFallback uses the release description of versions that
+	// are missing from the CHANGELOG
+	ReleaseBodiesFallback = "fallback"
+	// ReleaseBodiesPrefer uses the release description of versions that have
+	// one and the CHANGELOG for the others
+	ReleaseBodiesPrefer = "prefer"
+)
+
 // Category represents a set of repositories that are logically part of the same
 // group
 type Category struct {
@@ -36,6 +71,15 @@ type Category struct {
 type Section struct {
 	describedObject `yaml:",inline"`
 	Categories      []Category
+	// SectionOrder is the order that changelog sections are listed in
+	SectionOrder []string `yaml:"section_order,omitempty"`
+	// SectionAliases maps variants of changelog section names to their
+	// canonical names, in addition to the built-in ones
+	SectionAliases map[string]string `yaml:"section_aliases,omitempty"`
+	// DateFormat is the Go time layout that release notes show dates in
+	DateFormat string `yaml:"date_format,omitempty"`
+	// ReleaseBodies is the default of the repos, see Repository
+	ReleaseBodies string `yaml:"release_bodies,omitempty"`
 }
 
 // Config is the toplevel object containing the layout of a suite.yml
@@ -109,3 +153,50 @@ func (config *Config) SelectUnreleased() {
 		}
 	}
 }
+
+// SetDefaultGitHubURLs modifies a Config in-place so that GitHub-hosted repos
+// use the specified API and raw content base URLs (e.g. of a GitHub Enterprise
+// instance). URLs that repos set themselves are kept, and each of the others is
+// filled in on its own, e.g. a repo that only sets `api_url` gets `rawURL`.
+func (config *Config) SetDefaultGitHubURLs(apiURL string, rawURL string) {
+	for _, category := range config.Section.Categories {
+		// We use indexes since modifying objects while using them doesn't work in Golang
+		// as expected.
+		// More info: https://github.com/golang/go/wiki/CommonMistakes#using-reference-to-loop-iterator-variable
+		for repoIndex, repo := range category.Repos {
+			if !provider.IsGitHub(repo.Provider) {
+				continue
+			}
+
+			remappedRepo := repo
+			if remappedRepo.APIURL == "" {
+				remappedRepo.APIURL = apiURL
+			}
+			if remappedRepo.RawURL == "" {
+				remappedRepo.RawURL = rawURL
+			}
+
+			category.Repos[repoIndex] = remappedRepo
+		}
+	}
+}
+
+// UseLocalClones modifies a Config in-place so that every repo is read from
+// the local git clones in `cloneDir` rather than from its hosting service
+func (config *Config) UseLocalClones(cloneDir string) {
+	for _, category := range config.Section.Categories {
+		// We use indexes since modifying objects while using them doesn't work in Golang
+		// as expected.
+		// More info: https://github.com/golang/go/wiki/CommonMistakes#using-reference-to-loop-iterator-variable
+		for repoIndex, repo := range category.Repos {
+			remappedRepo := repo
+			remappedRepo.Provider = "local"
+			remappedRepo.CloneDir = cloneDir
+			remappedRepo.APIURL = ""
+			remappedRepo.RawURL = ""
+			remappedRepo.BaseURL = ""
+
+			category.Repos[repoIndex] = remappedRepo
+		}
+	}
+}
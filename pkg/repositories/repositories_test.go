@@ -152,3 +152,60 @@ func TestSelectUnreleased(t *testing.T) {
 
 	assert.Equal(t, expectedConfig, config)
 }
+
+func TestSetDefaultGitHubURLs(t *testing.T) {
+	config, err := NewConfig("./testdata/mixed_hosts_suite.yml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	config.SetDefaultGitHubURLs("https://ghe.example.com/api/v3", "https://ghe.example.com/raw")
+
+	repos := config.Section.Categories[0].Repos
+
+	assert.Equal(t, "https://ghe.example.com/api/v3", repos[0].APIURL)
+	assert.Equal(t, "https://ghe.example.com/raw", repos[0].RawURL)
+
+	// Other providers don't support custom GitHub URLs
+	assert.Equal(t, "", repos[1].APIURL)
+	assert.Equal(t, "", repos[1].RawURL)
+	assert.Equal(t, "https://gitlab.example.com", repos[1].BaseURL)
+
+	// Per-repo URLs take precedence, and only the missing one is filled in
+	assert.Equal(t, "https://other-ghe.example.com/api/v3", repos[2].APIURL)
+	assert.Equal(t, "https://ghe.example.com/raw", repos[2].RawURL)
+}
+
+func TestSetDefaultGitHubURLsOnlyAPIURL(t *testing.T) {
+	config, err := NewConfig("./testdata/mixed_hosts_suite.yml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	config.SetDefaultGitHubURLs("https://ghe.example.com/api/v3", "")
+
+	repos := config.Section.Categories[0].Repos
+
+	assert.Equal(t, "https://ghe.example.com/api/v3", repos[0].APIURL)
+	assert.Equal(t, "", repos[0].RawURL)
+
+	// The repo that only sets an API URL keeps it
+	assert.Equal(t, "https://other-ghe.example.com/api/v3", repos[2].APIURL)
+	assert.Equal(t, "", repos[2].RawURL)
+}
+
+func TestUseLocalClones(t *testing.T) {
+	config, err := NewConfig("./testdata/mixed_hosts_suite.yml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	config.UseLocalClones("/src")
+
+	for _, repo := range config.Section.Categories[0].Repos {
+		assert.Equal(t, "local", repo.Provider)
+		assert.Equal(t, "/src", repo.CloneDir)
+		assert.Equal(t, "", repo.APIURL)
+		assert.Equal(t, "", repo.BaseURL)
+	}
+}
@@ -0,0 +1,104 @@
+package template
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	markdownparser "github.com/gomarkdown/markdown/parser"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
+)
+
+var docsURLRegexp = regexp.MustCompile(`docs\.(conjur|cyberark)\.(org|com)`)
+
+// htmlLink creates a link that opens in a new window, unless it points at the
+// docs
+func htmlLink(url string, text string) string {
+	if docsURLRegexp.MatchString(url) {
+		return fmt.Sprintf(`<a href="%s">%s</a>`, url, text)
+	}
+
+	return fmt.Sprintf(`<a href="%s" target="_blank">%s</a>`, url, text)
+}
+
+// markdownToHTML converts the inline markdown of a changelog entry to HTML.
+// Like markdownHyperlinksToHTMLHyperlinks, plain text is left as-is.
+func markdownToHTML(markdown string) string {
+	document := markdownparser.New().Parse([]byte(markdown))
+
+	var paragraphs []string
+	for _, block := range document.GetChildren() {
+		paragraphs = append(paragraphs, inlineHTML(block))
+	}
+
+	return strings.Join(paragraphs, "\n")
+}
+
+// inlineHTML converts a node with inline markup to HTML
+func inlineHTML(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.Text:
+		return string(n.Literal)
+	case *ast.Code:
+		return "<code>" + html.EscapeString(string(n.Literal)) + "</code>"
+	case *ast.Softbreak:
+		return "\n"
+	case *ast.Hardbreak:
+		return "<br>\n"
+	case *ast.Emph:
+		return "<em>" + inlineChildrenHTML(n) + "</em>"
+	case *ast.Strong:
+		return "<strong>" + inlineChildrenHTML(n) + "</strong>"
+	case *ast.Del:
+		return "<del>" + inlineChildrenHTML(n) + "</del>"
+	case *ast.Link:
+		return htmlLink(string(n.Destination), inlineChildrenHTML(n))
+	case *ast.Image:
+		return fmt.Sprintf(
+			`<img src="%s" alt="%s">`,
+			html.EscapeString(string(n.Destination)),
+			html.EscapeString(inlineChildrenHTML(n)),
+		)
+	}
+
+	if leaf := node.AsLeaf(); leaf != nil {
+		return string(leaf.Literal)
+	}
+
+	return inlineChildrenHTML(node)
+}
+
+// inlineChildrenHTML converts the children of a node to HTML
+func inlineChildrenHTML(node ast.Node) string {
+	res := ""
+	for _, child := range node.GetChildren() {
+		res += inlineHTML(child)
+	}
+
+	return res
+}
+
+// entriesToHTML renders changelog entries, including any nested ones, as an
+// HTML list
+func entriesToHTML(entries []changelog.Entry) string {
+	res := "<ul>"
+	for _, entry := range entries {
+		res += "<li><p>" + markdownToHTML(entry.Text) + "</p>"
+		if len(entry.Children) > 0 {
+			res += entriesToHTML(entry.Children)
+		}
+		res += "</li>"
+	}
+
+	return res + "</ul>"
+}
+
+// markdownTableCell makes text safe to use as a markdown table cell by
+// escaping pipes and joining its lines
+func markdownTableCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.Join(strings.Fields(strings.ReplaceAll(text, "\n", " ")), " ")
+}
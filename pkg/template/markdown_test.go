@@ -0,0 +1,71 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	testData := []struct {
+		description    string
+		inputString    string
+		expectedString string
+	}{
+		{
+			description:    "plain text",
+			inputString:    "foo (bar) & baz",
+			expectedString: "foo (bar) & baz",
+		},
+		{
+			description:    "inline markup",
+			inputString:    "**foo** *bar* ~~baz~~ `<qux>`",
+			expectedString: "<strong>foo</strong> <em>bar</em> <del>baz</del> <code>&lt;qux&gt;</code>",
+		},
+		{
+			description:    "links",
+			inputString:    "foo [**bar**](baz) and [docs](https://docs.conjur.org/baz)",
+			expectedString: `foo <a href="baz" target="_blank"><strong>bar</strong></a> and <a href="https://docs.conjur.org/baz">docs</a>`,
+		},
+		{
+			description:    "multiple lines",
+			inputString:    "foo\nbar",
+			expectedString: "foo\nbar",
+		},
+	}
+
+	for _, td := range testData {
+		t.Run(td.description, func(t *testing.T) {
+			assert.Equal(t, td.expectedString, markdownToHTML(td.inputString))
+		})
+	}
+}
+
+func TestEntriesToHTML(t *testing.T) {
+	entries := []changelog.Entry{
+		{
+			Text: "Upgraded **all** dependencies:",
+			Children: []changelog.Entry{
+				{Text: "`puma`"},
+				{Text: "rack", Children: []changelog.Entry{{Text: "CVE-2020-8184"}}},
+			},
+		},
+	}
+
+	assert.Equal(
+		t,
+		"<ul><li><p>Upgraded <strong>all</strong> dependencies:</p>"+
+			"<ul><li><p><code>puma</code></p></li>"+
+			"<li><p>rack</p><ul><li><p>CVE-2020-8184</p></li></ul></li></ul>"+
+			"</li></ul>",
+		entriesToHTML(entries),
+	)
+}
+
+func TestMarkdownTableCell(t *testing.T) {
+	assert.Equal(t, "foo", markdownTableCell("foo"))
+	assert.Equal(t, `foo \| bar`, markdownTableCell("foo | bar"))
+	assert.Equal(t, "foo bar baz", markdownTableCell("foo\n  bar\nbaz"))
+}
@@ -1,6 +1,7 @@
 package template
 
 import (
+	"encoding/json"
 	"fmt"
 	htmlTemplate "html/template"
 	"os"
@@ -10,17 +11,25 @@ import (
 	textTemplate "text/template"
 	"time"
 
+	"github.com/cyberark/conjur-oss-suite-release/pkg/changelog"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/github"
 	"github.com/cyberark/conjur-oss-suite-release/pkg/log"
 )
 
 // ReleaseSuite stores all the data needed for generation of templates in the suite
 type ReleaseSuite struct {
-	Version          string
-	Date             time.Time
+	Version string
+	Date    time.Time
+	// DateFormat is the Go time layout that the release notes show dates in
+	DateFormat       string
 	Description      string
 	SuiteCategories  []github.SuiteCategory
 	UnifiedChangelog string
+	// SuiteChangelog is the keep a changelog markdown of the suite release
+	SuiteChangelog string
+	// Changes is the structured form of UnifiedChangelog, for templates that
+	// link, filter or group entries
+	Changes changelog.UnifiedChangelog
 }
 
 // MarkdownPartialsExt is the extension used for markdown partials glob matcher
@@ -44,6 +53,9 @@ var funcMap = map[string]interface{}{
 	"toLower":                            strings.ToLower,
 	"markdownHeaderLink":                 markdownHeaderLink,
 	"markdownHyperlinksToHTMLHyperlinks": markdownHyperlinksToHTMLHyperlinks,
+	"markdownToHTML":                     markdownToHTML,
+	"entriesToHTML":                      entriesToHTML,
+	"markdownTableCell":                  markdownTableCell,
 }
 
 func markdownHeaderLink(repo string) string {
@@ -140,14 +152,38 @@ func (r ReleaseSuite) ComponentReleaseVersion(repo string) string {
 	return ""
 }
 
-func markdownHyperlinksToHTMLHyperlinks(sectionItem string) string {
-	linkTemplate := `<a href="%s">%s</a>`
-	linkTemplateNewWindow := `<a href="%s" target="_blank">%s</a>`
+// securityBulletin is the JSON document of the security advisories of a suite
+// release
+type securityBulletin struct {
+	Version    string               `json:"version"`
+	Date       string               `json:"date"`
+	Advisories []changelog.Advisory `json:"advisories"`
+}
+
+// SecurityBulletin returns the security advisories of the suite release as a
+// JSON document, for security bulletins
+func (r ReleaseSuite) SecurityBulletin() (string, error) {
+	advisories := r.Changes.Advisories()
+	if advisories == nil {
+		advisories = []changelog.Advisory{}
+	}
+
+	bulletin, err := json.MarshalIndent(securityBulletin{
+		Version:    r.Version,
+		Date:       r.Date.Format(changelog.DefaultDateFormat),
+		Advisories: advisories,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(bulletin), nil
+}
 
+func markdownHyperlinksToHTMLHyperlinks(sectionItem string) string {
 	markdownRegex := regexp.MustCompile(`\[(.*?)\]\((.*?)\)`)
 	nameRegex := regexp.MustCompile(`\[(.*)\]`)
 	urlRegex := regexp.MustCompile(`\((.*)\)`)
-	docsURLRegex := regexp.MustCompile(`docs\.(conjur|cyberark)\.(org|com)`)
 
 	links := markdownRegex.FindAllString(sectionItem, -1)
 
@@ -160,13 +196,7 @@ func markdownHyperlinksToHTMLHyperlinks(sectionItem string) string {
 		name = strings.Replace(name, "[", "", 1)
 		name = strings.Replace(name, "]", "", 1)
 
-		// Only ask link to open in a new window if it's not a docs link
-		htmlLink := fmt.Sprintf(linkTemplateNewWindow, url, name)
-		if docsURLRegex.FindString(url) != "" {
-			htmlLink = fmt.Sprintf(linkTemplate, url, name)
-		}
-
-		sectionItem = strings.Replace(sectionItem, markdownLink, htmlLink, 1)
+		sectionItem = strings.Replace(sectionItem, markdownLink, htmlLink(url, name), 1)
 	}
 
 	return sectionItem
@@ -143,6 +143,31 @@ func HighestVersion(versions []string) (string, error) {
 	return highestVersionStr, nil
 }
 
+// PreviousVersion returns the highest version string from an array of version
+// strings that is lower than `versionStr`, or "" if there is none
+func PreviousVersion(versions []string, versionStr string) (string, error) {
+	version, err := versionFromString(versionStr)
+	if err != nil {
+		return "", err
+	}
+
+	var previousVersion *semver.Version
+	previousVersionStr := ""
+	for _, candidateStr := range versions {
+		candidate, err := versionFromString(candidateStr)
+		if err != nil {
+			return "", err
+		}
+
+		if candidate.LessThan(*version) && (previousVersion == nil || previousVersion.LessThan(*candidate)) {
+			previousVersion = candidate
+			previousVersionStr = candidateStr
+		}
+	}
+
+	return previousVersionStr, nil
+}
+
 // GetRelevantVersions sorts and returns the list of versions from highest
 // (included) to the lowest (excluded). The method auto-detects what's the
 // lower and what's the higher range bound.
@@ -435,3 +435,25 @@ func TestHighestVersionBadVersionsInArg(t *testing.T) {
 
 	assert.EqualError(t, err, "9 is not in dotted-tri format")
 }
+
+func TestPreviousVersion(t *testing.T) {
+	for version, expected := range map[string]string{
+		"v1.20.0": "v1.3.4",
+		"1.3.4":   "v1.3.3",
+		"v1.3.0":  "v0.29.20",
+		"v0.1.3":  "",
+	} {
+		previousVersion, err := PreviousVersion(versionFixtureData, version)
+		if assert.NoError(t, err, version) {
+			assert.Equal(t, expected, previousVersion, version)
+		}
+	}
+}
+
+func TestPreviousVersionBadVersion(t *testing.T) {
+	_, err := PreviousVersion(versionFixtureData, "abcd")
+	assert.EqualError(t, err, "abcd is not in dotted-tri format")
+
+	_, err = PreviousVersion([]string{"1.2.3", "9"}, "v1.3.0")
+	assert.EqualError(t, err, "9 is not in dotted-tri format")
+}
@@ -17,6 +17,22 @@ import (
 
 var templateExt = ".tmpl"
 
+func entries(texts ...string) []changelog.Entry {
+	res := []changelog.Entry{}
+	for _, text := range texts {
+		res = append(res, changelog.NewEntry("", text))
+	}
+
+	return res
+}
+
+func nestedEntry(text string, children ...string) changelog.Entry {
+	entry := changelog.NewEntry("", text)
+	entry.Children = entries(children...)
+
+	return entry
+}
+
 func getTemplatesInDir() ([]string, error) {
 	files, err := ioutil.ReadDir(".")
 	if err != nil {
@@ -57,15 +73,27 @@ func TestTemplates(t *testing.T) {
 		Version:          "11.22.33",
 		Description:      "A very special suite release.",
 		Date:             outputDate,
+		DateFormat:       "2006-01-02",
 		UnifiedChangelog: "@@@Unified changelog content@@@",
+		SuiteChangelog:   "@@@Suite changelog content@@@",
 		SuiteCategories: []github.SuiteCategory{
 			github.SuiteCategory{
 				CategoryName: "Conjur Core",
 				Components: []github.SuiteComponent{
 					github.SuiteComponent{
-						Repo:                 "cyberark/conjur",
-						URL:                  "https://github.com/cyberark/conjur",
+						Repo: "cyberark/conjur",
+						URL:  "https://github.com/cyberark/conjur",
+						UnreleasedChangelog: &changelog.VersionChangelog{
+							Repo:       "cyberark/conjur",
+							Version:    changelog.UnreleasedVersion,
+							Unreleased: true,
+							Sections: []changelog.Section{
+								{Name: "Added", Entries: entries("HEADAddition")},
+								{Name: "Fixed", Entries: entries("HEADFix", "HEADFix2")},
+							},
+						},
 						UnreleasedChangesURL: "https://github.com/cyberark/conjur/compare/v1.4.4...HEAD",
+						PreviousReleaseName:  "v1.3.5",
 						ReleaseName:          "v1.4.4",
 						ReleaseDate:          conjurReleaseDate2.Format("2006-01-02"),
 						CertificationLevel:   "trusted",
@@ -76,20 +104,22 @@ func TestTemplates(t *testing.T) {
 								Version: "1.3.6",
 								// Why are these strings?
 								Date: conjurReleaseDate1.Format("2006-01-02"),
-								Sections: map[string][]string{
-									"Changed": []string{"136Change", "136Change2"},
-									"Removed": []string{"136Removal"},
+								Sections: []changelog.Section{
+									{Name: "Changed", Entries: entries("136Change", "136Change2")},
+									{Name: "Removed", Entries: entries("136Removal")},
 								},
 							},
 							&changelog.VersionChangelog{
 								Repo:    "cyberark/conjur",
 								Version: "1.4.4",
+								URL:     "https://github.com/cyberark/conjur/compare/v1.4.3...v1.4.4",
 								// Why are these strings?
 								Date: conjurReleaseDate2.Format("2006-01-02"),
-								Sections: map[string][]string{
-									"Added":   []string{"144Addition", "144Addition2"},
-									"Changed": []string{"144Change", "144Change2"},
-									"Fixed":   []string{"144Fix"},
+								Sections: []changelog.Section{
+									{Name: "Added", Entries: entries("144Addition", "144Addition2")},
+									{Name: "Changed", Entries: entries("144Change", "144Change2")},
+									{Name: "Fixed", Entries: entries("144Fix")},
+									{Name: "Security", Entries: entries("144Security | CVE-2020-1234", "144Security Without An ID")},
 								},
 							},
 						},
@@ -109,20 +139,26 @@ func TestTemplates(t *testing.T) {
 				CategoryName: "Secrets Delivery",
 				Components: []github.SuiteComponent{
 					github.SuiteComponent{
-						Repo:               "cyberark/secretless-broker",
-						URL:                "https://github.com/cyberark/secretless-broker",
-						ReleaseName:        "v1.4.2",
-						ReleaseDate:        secretlessReleaseDate.Format("2006-01-02"),
-						CertificationLevel: "certified",
+						Repo:                "cyberark/secretless-broker",
+						URL:                 "https://github.com/cyberark/secretless-broker",
+						PreviousReleaseName: "v0.9.0",
+						ReleaseName:         "v1.4.2",
+						ReleaseDate:         secretlessReleaseDate.Format("2006-01-02"),
+						CertificationLevel:  "certified",
 						Changelogs: []*changelog.VersionChangelog{
 							&changelog.VersionChangelog{
-								Repo:    "cyberark/secretless-broker",
-								Version: "1.4.2",
-								Date:    secretlessReleaseDate.Format("2006-01-02"),
-								Sections: map[string][]string{
-									"Added":   []string{"Broker142Addition", "Broker142Addition With Link [my link](https://github.com/cyberark/conjur/issues/142)"},
-									"Changed": []string{"Broker142Change", "Broker142Change With Conjur Docs Link [my link](https://docs.conjur.org/sub-url)"},
-									"Removed": []string{"Broker142Removal", "Broker142Removal With CyberArk Docs Link [my link](https://docs.cyberark.com/sub-url)"},
+								Repo:            "cyberark/secretless-broker",
+								Version:         "1.4.2",
+								Date:            secretlessReleaseDate.Format("2006-01-02"),
+								FromReleaseBody: true,
+								Sections: []changelog.Section{
+									{Name: "Added", Entries: append(
+										entries("Broker142Addition", "Broker142Addition With Link [my link](https://github.com/cyberark/conjur/issues/142)"),
+										nestedEntry("Broker142Addition With **Sub-items**", "`Broker142SubItem`", "Broker142SubItem2"),
+									)},
+									{Name: "Changed", Entries: entries("Broker142Change", "Broker142Change With Conjur Docs Link [my link](https://docs.conjur.org/sub-url)")},
+									{Name: "Removed", Entries: entries("Broker142Removal", "Broker142Removal With CyberArk Docs Link [my link](https://docs.cyberark.com/sub-url)")},
+									{Name: "Fixed", Entries: entries("Broker142Fix for [GHSA-j6w9-fv6q-3q52](https://github.com/cyberark/secretless-broker/security/advisories/GHSA-j6w9-fv6q-3q52)")},
 								},
 							},
 						},
@@ -132,6 +168,14 @@ func TestTemplates(t *testing.T) {
 		},
 	}
 
+	var changelogs []*changelog.VersionChangelog
+	for _, category := range testData.SuiteCategories {
+		for _, component := range category.Components {
+			changelogs = append(changelogs, component.Changelogs...)
+		}
+	}
+	testData.Changes = changelog.NewUnifiedChangelog(changelog.DefaultSectionOrder, changelogs...)
+
 	for _, tt := range templates {
 		t.Run(tt, func(t *testing.T) {
 			outputFile := filepath.Join(dir, tt+"_output")